@@ -1,66 +1,464 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/takagi_hisashi/go-best-practice/web-api/config"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/admin"
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database"
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database/repository"
-	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/server"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/datasource"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/health"
 	infraHTTP "github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/http"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/jwt"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/lifecycle"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/metrics"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/oidc"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/password"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/server"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/tracing"
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/handler"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/middleware"
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/router"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/gateway/fallback"
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/gateway/jsonplaceholder"
+	accountUseCase "github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/account"
+	apiKeyUseCase "github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/apikey"
+	syncUseCase "github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/datasync"
 	postUseCase "github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/post"
 	userUseCase "github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/user"
+	"gorm.io/gorm"
 )
 
+// version is set at build time with -ldflags "-X main.version=...".
+var version = "dev"
+
 func main() {
 	// Load configuration
-	cfg := config.Load()
-
-	// Setup database connection
-	db, err := database.Connect()
+	loader, err := config.NewLoader(os.Args[0], os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		log.Fatal(err)
+	}
+	if len(loader.Args) > 0 {
+		log.Fatalf("unexpected arguments: %s", strings.Join(loader.Args, " "))
+	}
+	cfg, err := loader.Load()
+	if loader.PrintConfig {
+		out, printErr := cfg.Redacted()
+		if printErr != nil {
+			log.Fatal(printErr)
+		}
+		os.Stdout.Write(out)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if loader.PrintConfig {
+		return
 	}
 
-	// Run migrations
-	if err := database.RunMigrations(db); err != nil {
-		log.Fatal("Failed to run migrations:", err)
+	// Setup logging. The standard logger is routed through slog as well.
+	// The level can be changed at runtime on the admin listener.
+	logLevel := new(slog.LevelVar)
+	if err := logLevel.UnmarshalText([]byte(cfg.Log.Level)); err != nil {
+		log.Fatal("Invalid LOG_LEVEL:", err)
 	}
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel}))
+	slog.SetDefault(logger)
 
-	// Seed initial data
-	if err := database.SeedData(db); err != nil {
-		log.Fatal("Failed to seed data:", err)
+	// Setup config reloading. Settings that can change while running are
+	// applied by the listeners registered below.
+	reloader := config.NewReloader(loader, cfg, logger)
+	reloader.OnReload(func(prev, next *config.Config) {
+		if next.Log.Level != prev.Log.Level {
+			logLevel.UnmarshalText([]byte(next.Log.Level))
+		}
+	})
+
+	// Setup lifecycle. Resources are registered as they are created and
+	// released in order on shutdown.
+	app := lifecycle.New(logger, cfg.Shutdown.DrainDelay, cfg.Shutdown.Timeout, cfg.Shutdown.CloseTimeout)
+
+	// Setup metrics and tracing
+	registry := metrics.NewRegistry()
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Fatal("Failed to setup tracing:", err)
+	}
+	app.OnClose("tracing", shutdownTracing)
+
+	// Setup the database when a resource is kept in it.
+	var db *gorm.DB
+	var migrator *database.Migrator
+	if datasource.NeedsDatabase(cfg.Source.Users, cfg.Source.Posts) {
+		db, migrator = setupDatabase(cfg, app, registry, logger)
 	}
 
 	// Setup infrastructure
 	httpClient := infraHTTP.NewHTTPClient()
+	// The gateway timeout is enforced by the transport, so that it can be
+	// changed without replacing the client.
+	gatewayClient := infraHTTP.NewHTTPClient()
+	gatewayTimeout := infraHTTP.NewTimeoutTransport(gatewayClient.Transport, cfg.Gateway.Timeout)
+	gatewayClient.Transport = gatewayTimeout
+	gatewayClient.Timeout = 0
+	metrics.NewClientMetrics(registry).Instrument(gatewayClient, "jsonplaceholder")
+	reloader.OnReload(func(prev, next *config.Config) {
+		gatewayTimeout.SetTimeout(next.Gateway.Timeout)
+	})
 
-	// Setup repositories (database-based)
-	userRepo := repository.NewUserRepository(db)
-	postRepo := repository.NewPostRepository(db)
+	// Setup gateways for the external API. Its users and posts are
+	// imported periodically when a sync interval is configured.
+	postGateway := jsonplaceholder.NewPostGateway(cfg.Gateway.JSONPlaceholderURL, gatewayClient)
+	userGateway := jsonplaceholder.NewUserGateway(cfg.Gateway.JSONPlaceholderURL, gatewayClient)
+	if cfg.Sync.Interval > 0 && db == nil {
+		log.Println("JSONPlaceholder sync requires a database and is disabled")
+	} else if cfg.Sync.Interval > 0 {
+		syncService := syncUseCase.NewService(userGateway, postGateway, repository.NewImportRepository(db))
+		app.Go("jsonplaceholder-sync", func(ctx context.Context) {
+			runSync(ctx, syncService, cfg.Sync.Interval, cfg.Sync.DryRun, logger)
+		})
+	}
 
-	// Setup fallback gateways for external API (optional)
-	postGateway := jsonplaceholder.NewPostGateway(cfg.JSONPlaceholderURL, httpClient)
-	userGateway := jsonplaceholder.NewUserGateway(cfg.JSONPlaceholderURL, httpClient)
-	_ = postGateway // Use if needed for external data
-	_ = userGateway // Use if needed for external data
+	// Setup repositories from the source configured per resource
+	repos, err := datasource.New(datasource.Options{
+		Users:         cfg.Source.Users,
+		Posts:         cfg.Source.Posts,
+		UserFallback:  fallback.Policy(cfg.Fallback.Users),
+		UserWriteBack: cfg.Fallback.UsersWriteBack,
+		PostFallback:  fallback.Policy(cfg.Fallback.Posts),
+		PostWriteBack: cfg.Fallback.PostsWriteBack,
+		DB:            db,
+		UserGateway:   userGateway,
+		PostGateway:   postGateway,
+		Logger:        logger,
+	})
+	if err != nil {
+		log.Fatal("Failed to setup repositories:", err)
+	}
+	userRepo := repos.Users
+	postRepo := repos.Posts
+
+	// Users and posts kept in memory start from the seed data as well.
+	if cfg.Source.Users == datasource.Memory && cfg.Source.Posts == datasource.Memory {
+		if err := database.SeedRepositories(context.Background(), userRepo, postRepo, logger, seedOptions(cfg.Seed)); err != nil {
+			log.Fatal("Failed to seed data:", err)
+		}
+	}
 
-	// Setup use cases with database repositories
+	// Setup use cases
 	postService := postUseCase.NewService(postRepo)
 	userService := userUseCase.NewService(userRepo)
+	// Principals are always resolved from local accounts, never from a
+	// gateway whose users carry foreign IDs.
+	apiKeyService := apiKeyUseCase.NewService(repos.APIKeys, repos.Accounts)
 
 	// Setup handlers
 	postHandler := handler.NewPostHandler(postService)
 	userHandler := handler.NewUserHandler(userService)
+	adminHandler := handler.NewAdminHandler(userService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+
+	// Setup authentication. In dev mode without configured keys, tokens
+	// are signed with a key generated for this run.
+	jwtConfig := cfg.JWT
+	if loader.Dev && !jwtConfig.Enabled() {
+		dir, err := os.MkdirTemp("", "web-api-dev-keys")
+		if err != nil {
+			log.Fatal("Failed to generate JWT signing key:", err)
+		}
+		app.OnClose("dev-keys", func(context.Context) error {
+			return os.RemoveAll(dir)
+		})
+		if _, err := jwt.WriteSigningKey(dir); err != nil {
+			log.Fatal("Failed to generate JWT signing key:", err)
+		}
+		jwtConfig.SigningKeysPath = dir
+	}
+	authenticators := map[string]middleware.Authenticator{
+		"ApiKey": apiKeyService,
+	}
+	var authHandler *handler.AuthHandler
+	var oidcHandler *handler.OIDCHandler
+	var jwksHandler *handler.JWKSHandler
+	if jwtConfig.Enabled() {
+		keys, err := jwt.NewKeySet(jwtConfig.JWKSPath, jwtConfig.SigningKeysPath)
+		if err != nil {
+			log.Fatal("Failed to load JWT keys:", err)
+		}
+		app.Go("jwt-keys", func(ctx context.Context) {
+			keys.Watch(ctx, jwtConfig.KeysRefresh)
+		})
+
+		authenticators["Bearer"] = jwt.NewVerifier(keys, repos.Accounts, jwtConfig.Issuer, jwtConfig.Audience, jwtConfig.ClockSkew)
+		if keys.CanSign() {
+			hasher := password.NewArgon2idHasher(password.Params{
+				Memory:      uint32(cfg.Password.MemoryKiB),
+				Iterations:  uint32(cfg.Password.Iterations),
+				Parallelism: uint8(cfg.Password.Parallelism),
+				SaltLength:  password.DefaultParams.SaltLength,
+				KeyLength:   password.DefaultParams.KeyLength,
+			})
+			issuer := jwt.NewIssuer(keys, jwtConfig.Issuer, jwtConfig.Audience, jwtConfig.AccessTokenTTL)
+			accountService := accountUseCase.NewService(repos.Accounts, repos.RefreshTokens, hasher, issuer, jwtConfig.RefreshTokenTTL)
+
+			authHandler = handler.NewAuthHandler(accountService)
+
+			if cfg.OIDC.Enabled() {
+				oidcClient := oidc.NewClient(oidc.Config{
+					IssuerURL:    cfg.OIDC.IssuerURL,
+					ClientID:     cfg.OIDC.ClientID,
+					ClientSecret: cfg.OIDC.ClientSecret,
+					RedirectURL:  cfg.OIDC.RedirectURL,
+					Scopes:       cfg.OIDC.Scopes,
+					ClockSkew:    jwtConfig.ClockSkew,
+				}, httpClient)
+				ssoService := accountUseCase.NewSSOService(accountService, repos.Identities, oidcClient)
+
+				stateSecret := []byte(cfg.OIDC.StateSecret)
+				if len(stateSecret) == 0 {
+					log.Println("OIDC_STATE_SECRET is not set, using a random secret; logins cannot span replicas or restarts")
+					stateSecret = make([]byte, 32)
+					rand.Read(stateSecret)
+				}
+				// The login cookie is Secure when the browser reaches the
+				// callback over HTTPS, whether or not a proxy terminates TLS.
+				secureCookie := strings.HasPrefix(cfg.OIDC.RedirectURL, "https://")
+				oidcHandler = handler.NewOIDCHandler(ssoService, stateSecret, secureCookie)
+			}
+			jwksHandler = handler.NewJWKSHandler(keys)
+		}
+	}
+
+	if cfg.OIDC.Enabled() && oidcHandler == nil {
+		log.Println("OIDC login requires JWT signing keys and is disabled")
+	}
 
 	// Setup router
-	router := router.NewRouter(postHandler, userHandler)
+	router := router.NewRouter(postHandler, userHandler, adminHandler, apiKeyHandler, authHandler, oidcHandler, jwksHandler, jwtConfig.Enabled())
 	mux := router.Setup()
+	// Setup health checks
+	var checks []health.Check
+	if db != nil {
+		checks = append(checks,
+			health.DatabaseCheck(db, cfg.Health.CheckTimeout),
+			health.MigrationsCheck(migrator, cfg.Health.CheckTimeout),
+		)
+	}
+	if cfg.Health.CheckGateway {
+		checks = append(checks, health.HTTPCheck("jsonplaceholder", cfg.Gateway.JSONPlaceholderURL, httpClient, cfg.Health.CheckTimeout))
+	}
+	healthChecker := health.NewChecker(logger, cfg.Health.CacheTTL, checks...)
+	app.OnDrain(healthChecker.Drain)
+	mux.Handle("GET /healthz", healthChecker.Liveness())
+	mux.Handle("GET /readyz", healthChecker.Readiness())
+
+	// Setup middleware. Logs and limits group requests by the ServeMux
+	// pattern they are routed to.
+	routePattern := func(r *http.Request) string {
+		_, pattern := mux.Handler(r)
+		return pattern
+	}
+
+	// Setup rate limiting. Every IP address is limited before
+	// authentication, so that credentials cannot be tried at will. Clients
+	// are limited again after it, by API key or user before falling back
+	// to their IP. Health checks are never limited.
+	ipLimit, defaultLimit, routeLimits := rateLimits(cfg.RateLimit)
+	rateLimiter := middleware.NewRateLimiter(
+		ipLimit,
+		defaultLimit,
+		routeLimits,
+		cfg.RateLimit.TrustedProxies,
+		cfg.RateLimit.IdleTTL,
+		routePattern,
+		"GET /healthz", "GET /readyz",
+	)
+	app.Go("rate-limiter", rateLimiter.Run)
+	reloader.OnReload(func(prev, next *config.Config) {
+		ipLimit, defaultLimit, routeLimits := rateLimits(next.RateLimit)
+		rateLimiter.SetLimits(ipLimit, defaultLimit, routeLimits, next.RateLimit.TrustedProxies)
+	})
+
+	h := middleware.Chain(mux,
+		middleware.RequestID,
+		tracing.Middleware(routePattern),
+		middleware.AccessLog(logger, routePattern),
+		middleware.Metrics(metrics.NewHTTPMetrics(registry), routePattern),
+		middleware.Recover(logger),
+		rateLimiter.IPMiddleware,
+		middleware.Authenticate(authenticators),
+		rateLimiter.Middleware,
+	)
+
+	// Start server and wait for SIGINT or SIGTERM. A second signal
+	// terminates the process immediately.
+	srv, err := server.NewServer(fmt.Sprintf(":%d", cfg.Server.Port), h, server.Options{
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+		TLSCertFile:       cfg.Server.TLSCertFile,
+		TLSKeyFile:        cfg.Server.TLSKeyFile,
+		TLSReloadInterval: cfg.Server.TLSReloadInterval,
+		HTTP2:             cfg.Server.HTTP2,
+		H2C:               cfg.Server.H2C,
+	})
+	if err != nil {
+		log.Fatal("Failed to setup server:", err)
+	}
+	app.AddServer(srv)
+
+	// Start the admin listener for operational endpoints. It is not
+	// authenticated and binds to loopback unless configured otherwise.
+	if cfg.Admin.Enabled() {
+		adminHandler := middleware.Chain(admin.NewMux(admin.Options{
+			Metrics:   metrics.Handler(registry),
+			Readiness: healthChecker.Details(),
+			LogLevel:  logLevel,
+			Version:   version,
+		}), middleware.Recover(logger))
+
+		adminSrv, err := server.NewServer(cfg.Admin.Addr, adminHandler, server.Options{
+			ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+			ReadTimeout:       cfg.Server.ReadTimeout,
+			// CPU profiles and traces stream for as long as requested.
+			WriteTimeout:   2 * time.Minute,
+			IdleTimeout:    cfg.Server.IdleTimeout,
+			MaxHeaderBytes: cfg.Server.MaxHeaderBytes,
+		})
+		if err != nil {
+			log.Fatal("Failed to setup admin server:", err)
+		}
+		app.AddServer(adminSrv)
+	}
+
+	// Reload the config file when it changes or on SIGHUP.
+	app.Go("config", func(ctx context.Context) {
+		reloader.Watch(ctx, 5*time.Second)
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := app.Run(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// runSync imports from JSONPlaceholder right away and then every interval
+// until ctx is cancelled. Failures are logged and retried on the next tick.
+func runSync(ctx context.Context, service *syncUseCase.Service, interval time.Duration, dryRun bool, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := service.Sync(ctx, dryRun)
+		if err != nil {
+			logger.Error("JSONPlaceholder sync failed", "error", err)
+		} else {
+			logger.Info("JSONPlaceholder sync completed",
+				"dry_run", dryRun,
+				slog.Group("users", "created", result.Users.Created, "updated", result.Users.Updated, "unchanged", result.Users.Unchanged),
+				slog.Group("posts", "created", result.Posts.Created, "updated", result.Posts.Updated, "unchanged", result.Posts.Unchanged),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rateLimits converts the configured limits for the rate limiter.
+func rateLimits(cfg config.RateLimitConfig) (middleware.Limit, middleware.Limit, map[string]middleware.Limit) {
+	routeLimits := make(map[string]middleware.Limit, len(cfg.Routes))
+	for pattern, limit := range cfg.Routes {
+		routeLimits[pattern] = middleware.Limit{Rate: limit.RPS, Burst: limit.Burst}
+	}
+	return middleware.Limit{Rate: cfg.IPRPS, Burst: cfg.IPBurst}, middleware.Limit{Rate: cfg.RPS, Burst: cfg.Burst}, routeLimits
+}
+
+// setupDatabase connects to the database, applies or verifies the
+// migrations and seeds it. Failures are fatal.
+func setupDatabase(cfg *config.Config, app *lifecycle.App, registry *prometheus.Registry, logger *slog.Logger) (*gorm.DB, *database.Migrator) {
+	db, err := database.Connect(database.Options{
+		URL:                cfg.Database.URL,
+		MaxOpenConns:       cfg.Database.MaxOpenConns,
+		MaxIdleConns:       cfg.Database.MaxIdleConns,
+		ConnMaxLifetime:    cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime:    cfg.Database.ConnMaxIdleTime,
+		LogLevel:           cfg.Database.LogLevel,
+		SlowQueryThreshold: cfg.Database.SlowQueryThreshold,
+	})
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	app.OnClose("database", func(context.Context) error {
+		return database.Close()
+	})
+
+	if err := metrics.InstrumentDB(registry, db); err != nil {
+		log.Fatal("Failed to instrument database:", err)
+	}
+	if err := tracing.InstrumentDB(db); err != nil {
+		log.Fatal("Failed to instrument database:", err)
+	}
+
+	// Apply migrations when asked to; otherwise refuse to run against a
+	// schema this build does not match.
+	migrator, err := database.NewMigrator(db, logger)
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+	if cfg.Database.Migrate {
+		if err := migrator.Up(context.Background()); err != nil {
+			log.Fatal("Failed to run migrations:", err)
+		}
+	} else if err := migrator.Verify(context.Background()); err != nil {
+		log.Fatal("Database schema check failed, run cmd/migrate or set DATABASE_MIGRATE=true: ", err)
+	}
 
-	// Start server
-	srv := server.NewServer(cfg.ServerPort)
-	srv.Run(mux)
-}
\ No newline at end of file
+	// Seed initial data
+	if err := database.SeedData(context.Background(), db, logger, seedOptions(cfg.Seed)); err != nil {
+		log.Fatal("Failed to seed data:", err)
+	}
+
+	return db, migrator
+}
+
+func seedOptions(cfg config.SeedConfig) database.SeedOptions {
+	return database.SeedOptions{
+		Profile:      cfg.Profile,
+		Fixtures:     cfg.Fixtures,
+		Users:        cfg.Users,
+		PostsPerUser: cfg.PostsPerUser,
+	}
+}
@@ -0,0 +1,124 @@
+// Command migrate applies and rolls back the database migrations embedded in
+// the API. It reads the same configuration as the API:
+//
+//	migrate [flags] up              apply all pending migrations
+//	migrate [flags] down [N]        roll back the last N migrations, default 1
+//	migrate [flags] goto VERSION    migrate up or down to VERSION, 0 for none
+//	migrate [flags] status          list migrations and whether they are applied
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/config"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database"
+)
+
+const usage = "usage: migrate [flags] up | down [N] | goto VERSION | status"
+
+func main() {
+	loader, err := config.NewLoader(os.Args[0], os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, usage)
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(loader.Args) == 0 {
+		log.Fatal(usage)
+	}
+	cfg, err := loader.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	db, err := database.Connect(database.Options{
+		URL:                cfg.Database.URL,
+		MaxOpenConns:       1,
+		MaxIdleConns:       1,
+		LogLevel:           "warn",
+		SlowQueryThreshold: cfg.Database.SlowQueryThreshold,
+	})
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer database.Close()
+
+	migrator, err := database.NewMigrator(db, logger)
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, migrator, loader.Args); err != nil {
+		database.Close()
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, migrator *database.Migrator, args []string) error {
+	switch cmd, args := args[0], args[1:]; {
+	case cmd == "up" && len(args) == 0:
+		return migrator.Up(ctx)
+	case cmd == "down" && len(args) <= 1:
+		steps := 1
+		if len(args) == 1 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of migrations %q", args[0])
+			}
+			steps = n
+		}
+		return migrator.Down(ctx, steps)
+	case cmd == "goto" && len(args) == 1:
+		version, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+		return migrator.Goto(ctx, version)
+	case cmd == "status" && len(args) == 0:
+		return status(ctx, migrator)
+	}
+	return errors.New(usage)
+}
+
+func status(ctx context.Context, migrator *database.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, appliedAt := "pending", ""
+		if s.AppliedAt != nil {
+			state, appliedAt = "applied", s.AppliedAt.Local().Format(time.RFC3339)
+		}
+		switch {
+		case s.Modified:
+			state = "modified"
+		case s.Unknown:
+			state = "unknown"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,81 @@
+// Command sync imports the users and posts of JSONPlaceholder into the
+// database once and reports what changed. It reads the same configuration
+// as the API; --sync.dry_run only reports what would change.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/config"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database"
+	dbRepository "github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database/repository"
+	infraHTTP "github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/http"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/gateway/jsonplaceholder"
+	syncUseCase "github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/datasync"
+)
+
+func main() {
+	loader, err := config.NewLoader(os.Args[0], os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(loader.Args) > 0 {
+		log.Fatalf("unexpected arguments: %s", strings.Join(loader.Args, " "))
+	}
+	cfg, err := loader.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	db, err := database.Connect(database.Options{
+		URL:                cfg.Database.URL,
+		MaxOpenConns:       1,
+		MaxIdleConns:       1,
+		LogLevel:           "warn",
+		SlowQueryThreshold: cfg.Database.SlowQueryThreshold,
+	})
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer database.Close()
+
+	httpClient := infraHTTP.NewHTTPClient()
+	httpClient.Timeout = cfg.Gateway.Timeout
+	service := syncUseCase.NewService(
+		jsonplaceholder.NewUserGateway(cfg.Gateway.JSONPlaceholderURL, httpClient),
+		jsonplaceholder.NewPostGateway(cfg.Gateway.JSONPlaceholderURL, httpClient),
+		dbRepository.NewImportRepository(db),
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := service.Sync(ctx, cfg.Sync.DryRun)
+	if err != nil {
+		database.Close()
+		log.Fatal("Sync failed: ", err)
+	}
+
+	if cfg.Sync.DryRun {
+		fmt.Println("Dry run, nothing was written.")
+	}
+	report("users", result.Users)
+	report("posts", result.Posts)
+}
+
+func report(name string, counts repository.ImportCounts) {
+	fmt.Printf("%s: %d created, %d updated, %d unchanged\n", name, counts.Created, counts.Updated, counts.Unchanged)
+}
@@ -1,33 +1,332 @@
+// Package config loads the typed configuration of the API. Every setting
+// has a default and can be set, in increasing order of precedence, in a
+// YAML file, in an environment variable or with a command line flag. See
+// loader.go for the details and config.example.yaml for all settings.
 package config
 
 import (
-	"os"
-	"strconv"
+	"time"
 )
 
+// Struct tags drive loading and printing:
+//
+//	yaml:   key in the config file; nested keys also name the flag, e.g.
+//	        --server.port
+//	env:    environment variable; NAME_FILE reads the value from a file
+//	secret: "true" redacts the value when printed, "url" only the password
+//	        of a connection URL
+//	reload: "true" applies a change to a running server, see Reloader
 type Config struct {
-	ServerPort        int
-	JSONPlaceholderURL string
+	Server    ServerConfig    `yaml:"server"`
+	Admin     AdminConfig     `yaml:"admin"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Seed      SeedConfig      `yaml:"seed"`
+	Gateway   GatewayConfig   `yaml:"gateway"`
+	Sync      SyncConfig      `yaml:"sync"`
+	Source    SourceConfig    `yaml:"source"`
+	Fallback  FallbackConfig  `yaml:"fallback"`
+	Log       LogConfig       `yaml:"log"`
+	JWT       JWTConfig       `yaml:"jwt"`
+	Password  PasswordConfig  `yaml:"password"`
+	OIDC      OIDCConfig      `yaml:"oidc"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Health    HealthConfig    `yaml:"health"`
+	Shutdown  ShutdownConfig  `yaml:"shutdown"`
 }
 
-func Load() *Config {
-	return &Config{
-		ServerPort:        getEnvAsInt("SERVER_PORT", 8080),
-		JSONPlaceholderURL: getEnv("JSONPLACEHOLDER_URL", "https://jsonplaceholder.typicode.com"),
-	}
+// ServerConfig configures the public HTTP listener. The timeouts protect
+// against slow clients holding connections open.
+type ServerConfig struct {
+	Port              int           `yaml:"port" env:"SERVER_PORT"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" env:"SERVER_MAX_HEADER_BYTES"`
+	// TLSCertFile and TLSKeyFile enable TLS. Renewed certificates are
+	// picked up within TLSReloadInterval.
+	TLSCertFile       string        `yaml:"tls_cert_file" env:"SERVER_TLS_CERT_FILE"`
+	TLSKeyFile        string        `yaml:"tls_key_file" env:"SERVER_TLS_KEY_FILE"`
+	TLSReloadInterval time.Duration `yaml:"tls_reload_interval" env:"SERVER_TLS_RELOAD_INTERVAL"`
+	HTTP2             bool          `yaml:"http2" env:"SERVER_HTTP2"`
+	// H2C serves HTTP/2 without TLS, for internal deployments behind a
+	// proxy that speaks it.
+	H2C bool `yaml:"h2c" env:"SERVER_H2C"`
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
+// AdminConfig configures the listener for metrics, pprof and other
+// operational endpoints. ADMIN_ADDR=off disables it.
+type AdminConfig struct {
+	Addr string `yaml:"addr" env:"ADMIN_ADDR"`
+}
+
+func (c AdminConfig) Enabled() bool {
+	return c.Addr != "" && c.Addr != "off"
+}
+
+// DatabaseConfig configures the connection pool. A zero MaxOpenConns means
+// no limit. URL is a postgres:// or mysql:// URL, or sqlite://PATH for a
+// local SQLite file, which is served through a single connection regardless
+// of the pool settings.
+type DatabaseConfig struct {
+	URL string `yaml:"url" env:"DATABASE_URL" secret:"url"`
+	// Migrate applies pending migrations on startup. Otherwise the API
+	// refuses to start until they are applied with cmd/migrate.
+	Migrate         bool          `yaml:"migrate" env:"DATABASE_MIGRATE"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DATABASE_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DATABASE_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DATABASE_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DATABASE_CONN_MAX_IDLE_TIME"`
+	// LogLevel is the GORM log level: silent, error, warn or info. Queries
+	// slower than SlowQueryThreshold are logged at warn.
+	LogLevel           string        `yaml:"log_level" env:"DATABASE_LOG_LEVEL"`
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env:"DATABASE_SLOW_QUERY_THRESHOLD"`
+}
+
+// SeedConfig selects the data inserted on startup. Profile is "none",
+// "demo" or "load-test", which generates Users users with PostsPerUser posts
+// each. Fixtures is a JSON or YAML file, or a directory of them, seeded in
+// addition to the profile.
+type SeedConfig struct {
+	Profile      string `yaml:"profile" env:"SEED_PROFILE"`
+	Fixtures     string `yaml:"fixtures" env:"SEED_FIXTURES"`
+	Users        int    `yaml:"users" env:"SEED_USERS"`
+	PostsPerUser int    `yaml:"posts_per_user" env:"SEED_POSTS_PER_USER"`
+}
+
+type GatewayConfig struct {
+	JSONPlaceholderURL string        `yaml:"jsonplaceholder_url" env:"JSONPLACEHOLDER_URL"`
+	Timeout            time.Duration `yaml:"timeout" env:"GATEWAY_TIMEOUT" reload:"true"`
 }
 
-func getEnvAsInt(key string, defaultValue int) int {
-	strValue := getEnv(key, "")
-	if value, err := strconv.Atoi(strValue); err == nil {
-		return value
+// SyncConfig configures the import of JSONPlaceholder users and posts. The
+// API runs it every Interval when that is positive; cmd/sync runs it once.
+// With DryRun the import only reports what it would change.
+type SyncConfig struct {
+	Interval time.Duration `yaml:"interval" env:"SYNC_INTERVAL"`
+	DryRun   bool          `yaml:"dry_run" env:"SYNC_DRY_RUN"`
+}
+
+// SourceConfig selects where each resource is stored: "postgres" (the
+// database at database.url, which may also be MySQL or SQLite),
+// "jsonplaceholder" (read-only), "memory" (lost on restart) or "fallback",
+// the database with JSONPlaceholder as configured in FallbackConfig.
+type SourceConfig struct {
+	Users string `yaml:"users" env:"SOURCE_USERS"`
+	Posts string `yaml:"posts" env:"SOURCE_POSTS"`
+}
+
+// FallbackConfig selects, per entity with the fallback source, when reads
+// turn to JSONPlaceholder: "outage" when the database fails, or "miss" also
+// when it lacks the record. With WriteBack, records found after a miss are
+// stored.
+type FallbackConfig struct {
+	Users          string `yaml:"users" env:"FALLBACK_USERS"`
+	UsersWriteBack bool   `yaml:"users_write_back" env:"FALLBACK_USERS_WRITE_BACK"`
+	Posts          string `yaml:"posts" env:"FALLBACK_POSTS"`
+	PostsWriteBack bool   `yaml:"posts_write_back" env:"FALLBACK_POSTS_WRITE_BACK"`
+}
+
+type LogConfig struct {
+	// Level is one of debug, info, warn or error.
+	Level string `yaml:"level" env:"LOG_LEVEL" reload:"true"`
+}
+
+type JWTConfig struct {
+	// JWKSPath is a JWKS file or a directory of them holding the keys
+	// accepted for token verification. Tokens of these keys act as no
+	// local user and carry no role.
+	JWKSPath string `yaml:"jwks_path" env:"JWT_JWKS_PATH"`
+	// SigningKeysPath is a PEM private key or a directory of them. When set
+	// the API issues its own tokens and publishes /.well-known/jwks.json.
+	SigningKeysPath string        `yaml:"signing_keys_path" env:"JWT_SIGNING_KEYS_PATH"`
+	Issuer          string        `yaml:"issuer" env:"JWT_ISSUER"`
+	Audience        string        `yaml:"audience" env:"JWT_AUDIENCE"`
+	ClockSkew       time.Duration `yaml:"clock_skew" env:"JWT_CLOCK_SKEW"`
+	KeysRefresh     time.Duration `yaml:"keys_refresh_interval" env:"JWT_KEYS_REFRESH_INTERVAL"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl" env:"JWT_ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl" env:"JWT_REFRESH_TOKEN_TTL"`
+}
+
+// Enabled reports whether bearer token authentication is configured.
+func (c JWTConfig) Enabled() bool {
+	return c.JWKSPath != "" || c.SigningKeysPath != ""
+}
+
+// PasswordConfig holds the argon2id cost parameters for new password hashes.
+// Existing hashes are upgraded on the next successful login.
+type PasswordConfig struct {
+	MemoryKiB   int `yaml:"argon2_memory_kib" env:"PASSWORD_ARGON2_MEMORY_KIB"`
+	Iterations  int `yaml:"argon2_iterations" env:"PASSWORD_ARGON2_ITERATIONS"`
+	Parallelism int `yaml:"argon2_parallelism" env:"PASSWORD_ARGON2_PARALLELISM"`
+}
+
+// OIDCConfig configures login through an external OpenID Connect provider.
+// It requires a JWT signing key, since the API issues its own tokens after
+// the login.
+type OIDCConfig struct {
+	IssuerURL    string   `yaml:"issuer_url" env:"OIDC_ISSUER_URL"`
+	ClientID     string   `yaml:"client_id" env:"OIDC_CLIENT_ID"`
+	ClientSecret string   `yaml:"client_secret" env:"OIDC_CLIENT_SECRET" secret:"true"`
+	RedirectURL  string   `yaml:"redirect_url" env:"OIDC_REDIRECT_URL"`
+	Scopes       []string `yaml:"scopes" env:"OIDC_SCOPES"`
+	// StateSecret signs the login state cookie. It must be shared by all
+	// replicas; a random secret is used when it is empty.
+	StateSecret string `yaml:"state_secret" env:"OIDC_STATE_SECRET" secret:"true"`
+}
+
+func (c OIDCConfig) Enabled() bool {
+	return c.IssuerURL != ""
+}
+
+// RateLimit is a token bucket refilled at RPS requests per second up to
+// Burst requests.
+type RateLimit struct {
+	RPS   float64 `yaml:"rps"`
+	Burst int     `yaml:"burst"`
+}
+
+// RateLimitConfig configures per-client rate limiting. RPS and Burst apply
+// to routes without an entry in Routes. A zero RPS disables limiting.
+// IPRPS and IPBurst limit each IP address before authentication, whoever
+// it signs in as; they should allow for clients sharing an address.
+type RateLimitConfig struct {
+	IPRPS   float64 `yaml:"ip_rps" env:"RATE_LIMIT_IP_RPS" reload:"true"`
+	IPBurst int     `yaml:"ip_burst" env:"RATE_LIMIT_IP_BURST" reload:"true"`
+	RPS     float64 `yaml:"rps" env:"RATE_LIMIT_RPS" reload:"true"`
+	Burst   int     `yaml:"burst" env:"RATE_LIMIT_BURST" reload:"true"`
+	// Routes are keyed by their pattern, e.g. "POST /auth/login". In the
+	// environment they are written "POST /auth/login=0.2:5;GET /posts=20:40".
+	Routes RouteLimits `yaml:"routes" env:"RATE_LIMIT_ROUTES" reload:"true"`
+	// TrustedProxies are the addresses allowed to set X-Forwarded-For.
+	TrustedProxies Prefixes `yaml:"trusted_proxies" env:"RATE_LIMIT_TRUSTED_PROXIES" reload:"true"`
+	// IdleTTL is how long the bucket of an inactive client is kept.
+	IdleTTL time.Duration `yaml:"idle_ttl" env:"RATE_LIMIT_IDLE_TTL"`
+}
+
+// TracingConfig selects where spans are exported: "otlp", "stdout" or
+// "none". The OTLP endpoint is read from the standard OTEL_EXPORTER_OTLP_*
+// variables.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// HealthConfig configures the readiness checks. The gateway check is off by
+// default, since the API serves from the database without it. The result
+// of the checks is reused for CacheTTL, so that probing readiness does not
+// load the dependencies.
+type HealthConfig struct {
+	CheckTimeout time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT"`
+	CheckGateway bool          `yaml:"check_gateway" env:"HEALTH_CHECK_GATEWAY"`
+	CacheTTL     time.Duration `yaml:"cache_ttl" env:"HEALTH_CACHE_TTL"`
+}
+
+// ShutdownConfig controls graceful shutdown. Readiness fails for DrainDelay
+// before the servers stop accepting, so that load balancers can stop
+// routing to the instance; in-flight requests then get up to Timeout to
+// finish. Each resource, such as the database, then gets up to CloseTimeout
+// to close.
+type ShutdownConfig struct {
+	DrainDelay   time.Duration `yaml:"drain_delay" env:"SHUTDOWN_DRAIN_DELAY"`
+	Timeout      time.Duration `yaml:"timeout" env:"SHUTDOWN_TIMEOUT"`
+	CloseTimeout time.Duration `yaml:"close_timeout" env:"SHUTDOWN_CLOSE_TIMEOUT"`
+}
+
+// Default returns the configuration used for every setting that is not set
+// explicitly.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:              8080,
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			MaxHeaderBytes:    64 << 10,
+			TLSReloadInterval: time.Minute,
+			HTTP2:             true,
+		},
+		Admin: AdminConfig{
+			Addr: "127.0.0.1:9090",
+		},
+		Database: DatabaseConfig{
+			MaxOpenConns:       25,
+			MaxIdleConns:       10,
+			ConnMaxLifetime:    30 * time.Minute,
+			ConnMaxIdleTime:    5 * time.Minute,
+			LogLevel:           "info",
+			SlowQueryThreshold: 200 * time.Millisecond,
+		},
+		Seed: SeedConfig{
+			Profile:      "demo",
+			Users:        1000,
+			PostsPerUser: 10,
+		},
+		Gateway: GatewayConfig{
+			JSONPlaceholderURL: "https://jsonplaceholder.typicode.com",
+			Timeout:            30 * time.Second,
+		},
+		Source: SourceConfig{
+			Users: "postgres",
+			Posts: "postgres",
+		},
+		Fallback: FallbackConfig{
+			Users: "miss",
+			Posts: "miss",
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+		JWT: JWTConfig{
+			ClockSkew:       30 * time.Second,
+			KeysRefresh:     time.Minute,
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+		},
+		Password: PasswordConfig{
+			MemoryKiB:   64 * 1024,
+			Iterations:  3,
+			Parallelism: 2,
+		},
+		OIDC: OIDCConfig{
+			Scopes: []string{"openid", "email", "profile"},
+		},
+		RateLimit: RateLimitConfig{
+			IPRPS:   50,
+			IPBurst: 100,
+			RPS:     10,
+			Burst:   20,
+			Routes:  RouteLimits{},
+			IdleTTL: 10 * time.Minute,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "web-api",
+			SampleRatio: 1,
+		},
+		Health: HealthConfig{
+			CheckTimeout: 2 * time.Second,
+			CacheTTL:     time.Second,
+		},
+		Shutdown: ShutdownConfig{
+			DrainDelay:   5 * time.Second,
+			Timeout:      30 * time.Second,
+			CloseTimeout: 10 * time.Second,
+		},
 	}
-	return defaultValue
-}
\ No newline at end of file
+}
+
+// Dev returns the defaults of --dev. Users and posts are kept in memory and
+// seeded with the demo data, so the API starts without any other service,
+// and there is no drain delay to wait for on shutdown.
+func Dev() *Config {
+	cfg := Default()
+	cfg.Source = SourceConfig{Users: "memory", Posts: "memory"}
+	cfg.Seed.Profile = "demo"
+	cfg.Log.Level = "debug"
+	cfg.Shutdown.DrainDelay = 0
+	return cfg
+}
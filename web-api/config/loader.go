@@ -0,0 +1,249 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Loader builds the configuration from, in increasing order of precedence:
+//
+//  1. the defaults of Default, or of Dev with --dev;
+//  2. the YAML file given with --config or CONFIG_FILE;
+//  3. environment variables, where NAME_FILE reads NAME from a file, for
+//     secrets mounted by the orchestrator;
+//  4. command line flags named after the YAML keys, e.g. --server.port.
+//
+// Unlike the sources, the command line is parsed once, so that a reload
+// picks up changes to the file and environment but keeps the flags.
+type Loader struct {
+	// File is the config file, or "" when there is none.
+	File string
+	// PrintConfig asks to print the effective configuration and exit.
+	PrintConfig bool
+	// Dev selects the defaults of Dev.
+	Dev bool
+	// Args are the arguments after the flags, for commands that take them.
+	Args []string
+
+	flags []flagValue
+}
+
+type flagValue struct {
+	name  string
+	value string
+}
+
+// NewLoader parses the command line. It returns flag.ErrHelp when help was
+// requested.
+func NewLoader(name string, args []string) (*Loader, error) {
+	l := &Loader{}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&l.File, "config", os.Getenv("CONFIG_FILE"), "YAML config `file` (env CONFIG_FILE)")
+	fs.BoolVar(&l.PrintConfig, "print-config", false, "print the effective configuration with secrets redacted and exit")
+	fs.BoolVar(&l.Dev, "dev", false, "run without a database: keep users and posts in memory, seeded with demo data")
+
+	for _, f := range fields(Default()) {
+		record := func(value string) error {
+			l.flags = append(l.flags, flagValue{name: f.path, value: value})
+			return nil
+		}
+		usage := "env " + f.env
+		if f.value.Kind() == reflect.Bool {
+			fs.BoolFunc(f.path, usage, func(value string) error { return record(value) })
+		} else {
+			fs.Func(f.path, usage, record)
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	l.Args = fs.Args()
+	return l, nil
+}
+
+// Load reads all sources and validates the result. When there are problems
+// the returned error lists all of them, and the configuration is still
+// returned so that it can be printed.
+func (l *Loader) Load() (*Config, error) {
+	cfg := Default()
+	if l.Dev {
+		cfg = Dev()
+	}
+	var problems []string
+
+	if l.File != "" {
+		if err := loadFile(cfg, l.File); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	byPath := make(map[string]field)
+	for _, f := range fields(cfg) {
+		byPath[f.path] = f
+
+		value, ok, err := lookupEnv(f.env)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if err := setValue(f.value, value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", f.env, err))
+		}
+	}
+
+	for _, flag := range l.flags {
+		if err := setValue(byPath[flag.name].value, flag.value); err != nil {
+			problems = append(problems, fmt.Sprintf("--%s: %v", flag.name, err))
+		}
+	}
+
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return cfg, &Error{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// Error lists every problem found while loading the configuration.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+func loadFile(cfg *Config, name string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config file %s: %w", name, err)
+	}
+	return nil
+}
+
+// lookupEnv returns the value of name, or the content of the file named by
+// name_FILE. Empty variables count as unset.
+func lookupEnv(name string) (string, bool, error) {
+	value := os.Getenv(name)
+	file := os.Getenv(name + "_FILE")
+	switch {
+	case value != "" && file != "":
+		return "", false, fmt.Errorf("%s and %s_FILE are both set", name, name)
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", false, fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), true, nil
+	case value != "":
+		return value, true, nil
+	}
+	return "", false, nil
+}
+
+// field is a single setting of Config.
+type field struct {
+	path   string
+	env    string
+	secret string
+	reload bool
+	value  reflect.Value
+}
+
+// fields lists the settings of cfg in declaration order. Struct fields
+// without an env tag are sections and are walked recursively.
+func fields(cfg *Config) []field {
+	var out []field
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			key, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+			path := prefix + key
+
+			env, ok := sf.Tag.Lookup("env")
+			if !ok {
+				if sf.Type.Kind() == reflect.Struct {
+					walk(v.Field(i), path+".")
+				}
+				continue
+			}
+			out = append(out, field{
+				path:   path,
+				env:    env,
+				secret: sf.Tag.Get("secret"),
+				reload: sf.Tag.Get("reload") == "true",
+				value:  v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return out
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setValue parses s into v. Lists are separated by commas or spaces.
+func setValue(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(strings.TrimSpace(s))
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", s)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		items := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
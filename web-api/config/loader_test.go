@@ -0,0 +1,223 @@
+package config
+
+import (
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile writes content to a new file and returns its name.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func load(t *testing.T, args ...string) (*Config, error) {
+	t.Helper()
+
+	t.Setenv("CONFIG_FILE", "")
+	loader, err := NewLoader("api", append([]string{"--dev"}, args...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loader.Load()
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "config.yaml", "server:\n  port: 8081\n")
+
+	tests := []struct {
+		name string
+		file bool
+		env  string
+		flag string
+		want int
+	}{
+		{name: "default", want: 8080},
+		{name: "file", file: true, want: 8081},
+		{name: "env over file", file: true, env: "8082", want: 8082},
+		{name: "flag over env", file: true, env: "8082", flag: "8083", want: 8083},
+		{name: "flag over default", flag: "8083", want: 8083},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args []string
+			if tt.file {
+				args = append(args, "--config", file)
+			}
+			if tt.flag != "" {
+				args = append(args, "--server.port", tt.flag)
+			}
+			t.Setenv("SERVER_PORT", tt.env)
+
+			cfg, err := load(t, args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Server.Port != tt.want {
+				t.Errorf("server.port = %d, want %d", cfg.Server.Port, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadDevDefaults(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	loader, err := NewLoader("api", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.Load(); err == nil || !strings.Contains(err.Error(), "database.url is required") {
+		t.Errorf("Load without --dev = %v, want database.url required", err)
+	}
+
+	cfg, err := load(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Source.Users != "memory" || cfg.Seed.Profile != "demo" {
+		t.Errorf("--dev config = %+v %+v", cfg.Source, cfg.Seed)
+	}
+}
+
+func TestLoadTypes(t *testing.T) {
+	t.Setenv("GATEWAY_TIMEOUT", "3s")
+	t.Setenv("OIDC_SCOPES", "openid, email")
+	t.Setenv("RATE_LIMIT_TRUSTED_PROXIES", "10.0.0.0/8,192.0.2.1")
+	t.Setenv("RATE_LIMIT_ROUTES", "POST  /auth/login=1:5; GET /posts=20:40")
+
+	cfg, err := load(t, "--server.http2=false", "--tracing.sample_ratio", "0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Gateway.Timeout != 3*time.Second {
+		t.Errorf("gateway.timeout = %v", cfg.Gateway.Timeout)
+	}
+	if !slices.Equal(cfg.OIDC.Scopes, []string{"openid", "email"}) {
+		t.Errorf("oidc.scopes = %q", cfg.OIDC.Scopes)
+	}
+	wantProxies := Prefixes{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.0.2.1/32")}
+	if !slices.Equal(cfg.RateLimit.TrustedProxies, wantProxies) {
+		t.Errorf("rate_limit.trusted_proxies = %v", cfg.RateLimit.TrustedProxies)
+	}
+	if limit := cfg.RateLimit.Routes["POST /auth/login"]; limit != (RateLimit{RPS: 1, Burst: 5}) || len(cfg.RateLimit.Routes) != 2 {
+		t.Errorf("rate_limit.routes = %v", cfg.RateLimit.Routes)
+	}
+	if cfg.Server.HTTP2 || cfg.Tracing.SampleRatio != 0.5 {
+		t.Errorf("server.http2 = %v, tracing.sample_ratio = %v", cfg.Server.HTTP2, cfg.Tracing.SampleRatio)
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	secret := writeFile(t, "secret", "s3cr3t\n")
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    string
+		problem string
+	}{
+		{
+			name: "file",
+			env:  map[string]string{"OIDC_STATE_SECRET_FILE": secret},
+			want: "s3cr3t",
+		},
+		{
+			name:    "both set",
+			env:     map[string]string{"OIDC_STATE_SECRET": "inline", "OIDC_STATE_SECRET_FILE": secret},
+			problem: "OIDC_STATE_SECRET and OIDC_STATE_SECRET_FILE are both set",
+		},
+		{
+			name:    "missing file",
+			env:     map[string]string{"OIDC_STATE_SECRET_FILE": filepath.Join(t.TempDir(), "missing")},
+			problem: "OIDC_STATE_SECRET_FILE:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			cfg, err := load(t)
+			if tt.problem != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.problem) {
+					t.Fatalf("Load = %v, want %q", err, tt.problem)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.OIDC.StateSecret != tt.want {
+				t.Errorf("oidc.state_secret = %q, want %q", cfg.OIDC.StateSecret, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		problem string
+	}{
+		{"unknown key", "server:\n  prot: 8081\n", "field prot not found"},
+		{"unknown section", "servers:\n  port: 8081\n", "field servers not found"},
+		{"wrong type", "server:\n  port: eighty\n", "cannot unmarshal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := load(t, "--config", writeFile(t, "config.yaml", tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.problem) {
+				t.Errorf("Load = %v, want %q", err, tt.problem)
+			}
+		})
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		if _, err := load(t, "--config", writeFile(t, "config.yaml", "")); err != nil {
+			t.Errorf("Load = %v", err)
+		}
+	})
+	t.Run("missing", func(t *testing.T) {
+		if _, err := load(t, "--config", filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+			t.Error("Load accepted a missing file")
+		}
+	})
+}
+
+func TestLoadListsEveryProblem(t *testing.T) {
+	t.Setenv("SERVER_PORT", "eighty")
+	t.Setenv("GATEWAY_TIMEOUT", "soon")
+
+	cfg, err := load(t, "--log.level", "loud", "--server.idle_timeout", "0s")
+	var loadErr *Error
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("Load = %v, want *Error", err)
+	}
+	if cfg == nil {
+		t.Error("Load returned no config with its problems")
+	}
+	want := []string{
+		`SERVER_PORT: invalid integer "eighty"`,
+		`GATEWAY_TIMEOUT: invalid duration "soon"`,
+		"server.idle_timeout must be positive",
+		"log.level must be one of debug, info, warn or error",
+	}
+	if !slices.Equal(loadErr.Problems, want) {
+		t.Errorf("problems = %q, want %q", loadErr.Problems, want)
+	}
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "REDACTED"
+
+// Redacted returns the configuration as YAML in the format of the config
+// file, with secrets replaced.
+func (c *Config) Redacted() ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	sections := make(map[string]*yaml.Node)
+
+	for _, f := range fields(c) {
+		parent := root
+		section, key := "", f.path
+		if i := strings.LastIndex(f.path, "."); i >= 0 {
+			section, key = f.path[:i], f.path[i+1:]
+		}
+		if section != "" {
+			if sections[section] == nil {
+				sections[section] = &yaml.Node{Kind: yaml.MappingNode}
+				root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: section}, sections[section])
+			}
+			parent = sections[section]
+		}
+
+		value := &yaml.Node{}
+		if err := value.Encode(printable(f)); err != nil {
+			return nil, err
+		}
+		parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+
+	return yaml.Marshal(root)
+}
+
+func printable(f field) any {
+	if f.value.Type() == durationType {
+		return time.Duration(f.value.Int()).String()
+	}
+	if s, ok := f.value.Interface().(string); ok && s != "" {
+		switch f.secret {
+		case "true":
+			return redacted
+		case "url":
+			return redactURL(s)
+		}
+	}
+	return f.value.Interface()
+}
+
+var dsnPassword = regexp.MustCompile(`(password=)(?:'(?:[^'\\]|\\.)*'|\S+)`)
+
+// redactURL hides the password of a connection URL, in its user info or
+// its query, or of a key=value DSN.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" {
+		return dsnPassword.ReplaceAllString(s, "${1}"+redacted)
+	}
+
+	changed := false
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+		changed = true
+	}
+	if query := u.Query(); query.Has("password") {
+		query.Set("password", redacted)
+		u.RawQuery = query.Encode()
+		changed = true
+	}
+	if !changed {
+		return s
+	}
+	return u.String()
+}
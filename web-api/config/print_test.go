@@ -0,0 +1,69 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactURL(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"no password", "postgres://app@db:5432/api", "postgres://app@db:5432/api"},
+		{"no user", "sqlite://data/api.db", "sqlite://data/api.db"},
+		{"user password", "postgres://app:s3cr3t@db:5432/api?sslmode=disable", "postgres://app:REDACTED@db:5432/api?sslmode=disable"},
+		{"query password", "postgres://app@db/api?password=s3cr3t&sslmode=disable", "postgres://app@db/api?password=REDACTED&sslmode=disable"},
+		{"query password without user", "postgres://db/api?password=s3cr3t", "postgres://db/api?password=REDACTED"},
+		{"both", "mysql://app:s3cr3t@db/api?password=0th3r", "mysql://app:REDACTED@db/api?password=REDACTED"},
+		{"escaped password", "postgres://app:s3cr3t%40%3A@db/api", "postgres://app:REDACTED@db/api"},
+		{"dsn", "host=db user=app password=s3cr3t dbname=api", "host=db user=app password=REDACTED dbname=api"},
+		{"dsn quoted", "host=db password='s3 cr3t' dbname=api", "host=db password=REDACTED dbname=api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactURL(tt.in)
+			if got != tt.want {
+				t.Errorf("redactURL(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if strings.Contains(got, "s3") || strings.Contains(got, "0th3r") {
+				t.Errorf("redactURL(%q) = %q leaks the password", tt.in, got)
+			}
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.Database.URL = "postgres://app:secret@db/api"
+	cfg.OIDC.ClientSecret = "client-secret"
+	cfg.OIDC.ClientID = "api"
+	cfg.Server.Port = 9090
+
+	out, err := cfg.Redacted()
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(out)
+	if strings.Contains(text, "secret@") || strings.Contains(text, "client-secret") {
+		t.Errorf("Redacted leaks a secret:\n%s", text)
+	}
+	for _, want := range []string{
+		"url: postgres://app:REDACTED@db/api",
+		"client_secret: REDACTED",
+		"client_id: api",
+		"port: 9090",
+		"read_header_timeout: 5s",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Redacted lacks %q:\n%s", want, text)
+		}
+	}
+	// Empty secrets are printed as they are, so that it shows they are
+	// unset.
+	if !strings.Contains(text, `state_secret: ""`) {
+		t.Errorf("Redacted hides the unset state secret:\n%s", text)
+	}
+}
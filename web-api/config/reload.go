@@ -0,0 +1,163 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Reloader reloads the configuration when the config file changes or the
+// process receives SIGHUP. Only settings tagged reload:"true" take effect
+// while running; changes to any other setting are logged and ignored until
+// the next restart. A configuration that fails to load or validate is
+// rejected as a whole and the current one kept.
+type Reloader struct {
+	loader  *Loader
+	logger  *slog.Logger
+	current atomic.Pointer[Config]
+
+	mu        sync.Mutex
+	listeners []func(prev, next *Config)
+	modTime   time.Time
+	size      int64
+}
+
+// NewReloader starts from cfg, which must have been loaded by loader.
+func NewReloader(loader *Loader, cfg *Config, logger *slog.Logger) *Reloader {
+	r := &Reloader{loader: loader, logger: logger}
+	r.current.Store(cfg)
+	r.modTime, r.size = r.stat()
+	return r
+}
+
+// Current returns the configuration in effect.
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// OnReload registers fn to apply a new configuration to a running
+// component. Listeners are called in registration order after every
+// successful reload that changed a reloadable setting.
+func (r *Reloader) OnReload(fn func(prev, next *Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// Reload loads the configuration again and applies the reloadable changes.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	loaded, err := r.loader.Load()
+	if err != nil {
+		return err
+	}
+
+	prev := r.current.Load()
+	next := *prev
+	nextFields, loadedFields := fields(&next), fields(loaded)
+
+	var applied, ignored [][]any
+	for i, f := range nextFields {
+		value := loadedFields[i].value
+		if reflect.DeepEqual(f.value.Interface(), value.Interface()) {
+			continue
+		}
+
+		change := []any{"setting", f.path}
+		if f.secret == "" {
+			change = append(change, "old", fmt.Sprint(printable(f)), "new", fmt.Sprint(printable(loadedFields[i])))
+		}
+		if !f.reload {
+			ignored = append(ignored, change)
+			continue
+		}
+		f.value.Set(value)
+		applied = append(applied, change)
+	}
+
+	if problems := next.validate(); len(problems) > 0 {
+		return &Error{Problems: problems}
+	}
+
+	for _, change := range applied {
+		r.logger.Info("Config setting changed", change...)
+	}
+	for _, change := range ignored {
+		r.logger.Warn("Config setting changed, restart to apply", change...)
+	}
+	if len(applied) == 0 {
+		r.logger.Info("Config reloaded, no reloadable settings changed")
+		return nil
+	}
+
+	r.current.Store(&next)
+	for _, fn := range r.listeners {
+		fn(prev, &next)
+	}
+	r.logger.Info("Config reloaded", "changed", len(applied))
+	return nil
+}
+
+// Watch reloads on SIGHUP and when the config file has changed, which is
+// checked every interval, until ctx is cancelled. Failed reloads are logged.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			r.logger.Info("Reloading config on SIGHUP")
+		case <-ticker.C:
+			if !r.fileChanged() {
+				continue
+			}
+			r.logger.Info("Reloading config, file changed", "file", r.loader.File)
+		}
+
+		if err := r.Reload(); err != nil {
+			r.logger.Error("Config reload rejected, keeping current config", "error", err)
+		}
+	}
+}
+
+// fileChanged compares the config file with the last version seen. A file
+// that is being rewritten may be seen half written; the reload is rejected
+// and retried once the write has completed and changed the file again.
+func (r *Reloader) fileChanged() bool {
+	modTime, size := r.stat()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if modTime.Equal(r.modTime) && size == r.size {
+		return false
+	}
+	r.modTime, r.size = modTime, size
+	return true
+}
+
+func (r *Reloader) stat() (time.Time, int64) {
+	if r.loader.File == "" {
+		return time.Time{}, 0
+	}
+	info, err := os.Stat(r.loader.File)
+	if err != nil {
+		return time.Time{}, 0
+	}
+	return info.ModTime(), info.Size()
+}
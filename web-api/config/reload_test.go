@@ -0,0 +1,165 @@
+package config
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type reloadEnv struct {
+	file     string
+	reloader *Reloader
+	logs     *bytes.Buffer
+	// calls are the configurations the listener was called with.
+	calls [][2]*Config
+}
+
+func newReloadEnv(t *testing.T, content string) *reloadEnv {
+	t.Helper()
+
+	e := &reloadEnv{file: writeFile(t, "config.yaml", content), logs: &bytes.Buffer{}}
+	t.Setenv("CONFIG_FILE", "")
+	loader, err := NewLoader("api", []string{"--dev", "--config", e.file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.reloader = NewReloader(loader, cfg, slog.New(slog.NewTextHandler(e.logs, nil)))
+	e.reloader.OnReload(func(prev, next *Config) {
+		e.calls = append(e.calls, [2]*Config{prev, next})
+	})
+	return e
+}
+
+func (e *reloadEnv) write(t *testing.T, content string) {
+	t.Helper()
+	if err := os.WriteFile(e.file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const reloadBase = `
+server:
+  port: 8081
+log:
+  level: info
+gateway:
+  timeout: 5s
+oidc:
+  state_secret: first-secret
+`
+
+func TestReloadAppliesReloadableSettings(t *testing.T) {
+	e := newReloadEnv(t, reloadBase)
+	prev := e.reloader.Current()
+
+	e.write(t, `
+server:
+  port: 9090
+log:
+  level: warn
+gateway:
+  timeout: 7s
+oidc:
+  state_secret: second-secret
+`)
+	if err := e.reloader.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := e.reloader.Current()
+	if cfg.Log.Level != "warn" || cfg.Gateway.Timeout != 7*time.Second {
+		t.Errorf("reloadable settings = %q %v, want warn 7s", cfg.Log.Level, cfg.Gateway.Timeout)
+	}
+	if cfg.Server.Port != 8081 || cfg.OIDC.StateSecret != "first-secret" {
+		t.Errorf("restart settings = %d %q, want the old ones", cfg.Server.Port, cfg.OIDC.StateSecret)
+	}
+	if prev.Log.Level != "info" {
+		t.Errorf("previous config changed to %q", prev.Log.Level)
+	}
+
+	if len(e.calls) != 1 || e.calls[0][0] != prev || e.calls[0][1] != cfg {
+		t.Fatalf("listener calls = %v, want one with the previous and current config", e.calls)
+	}
+
+	logs := e.logs.String()
+	for _, want := range []string{
+		`msg="Config setting changed" setting=log.level old=info new=warn`,
+		`msg="Config setting changed, restart to apply" setting=server.port old=8081 new=9090`,
+		`msg="Config setting changed, restart to apply" setting=oidc.state_secret`,
+	} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("logs lack %q:\n%s", want, logs)
+		}
+	}
+	if strings.Contains(logs, "first-secret") || strings.Contains(logs, "second-secret") {
+		t.Errorf("logs leak a secret:\n%s", logs)
+	}
+}
+
+func TestReloadWithoutReloadableChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"unchanged", reloadBase},
+		{"restart settings only", strings.Replace(reloadBase, "port: 8081", "port: 9090", 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newReloadEnv(t, reloadBase)
+			prev := e.reloader.Current()
+
+			e.write(t, tt.content)
+			if err := e.reloader.Reload(); err != nil {
+				t.Fatal(err)
+			}
+			if e.reloader.Current() != prev {
+				t.Error("Current changed")
+			}
+			if len(e.calls) != 0 {
+				t.Errorf("listeners called %d times, want none", len(e.calls))
+			}
+			if !strings.Contains(e.logs.String(), "no reloadable settings changed") {
+				t.Errorf("logs:\n%s", e.logs)
+			}
+		})
+	}
+}
+
+func TestReloadRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"unknown key", reloadBase + "extra: true\n"},
+		{"malformed", "server: [\n"},
+		{"invalid reloadable value", strings.Replace(reloadBase, "level: info", "level: loud", 1)},
+		{"invalid restart value", strings.Replace(reloadBase, "port: 8081", "port: 0", 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newReloadEnv(t, reloadBase)
+			prev := e.reloader.Current()
+
+			e.write(t, strings.Replace(tt.content, "timeout: 5s", "timeout: 7s", 1))
+			if err := e.reloader.Reload(); err == nil {
+				t.Fatal("Reload accepted the config")
+			}
+			if cfg := e.reloader.Current(); cfg != prev || cfg.Gateway.Timeout != 5*time.Second {
+				t.Error("Current changed")
+			}
+			if len(e.calls) != 0 {
+				t.Errorf("listeners called %d times, want none", len(e.calls))
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RouteLimits maps route patterns to their rate limit. In the config file
+// it is a mapping; in the environment and on the command line it is written
+// as "PATTERN=RPS:BURST" entries separated by semicolons.
+type RouteLimits map[string]RateLimit
+
+func (l *RouteLimits) UnmarshalText(text []byte) error {
+	limits := make(RouteLimits)
+	for _, entry := range strings.Split(string(text), ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		pattern, limit, ok := strings.Cut(entry, "=")
+		rps, burst, ok2 := strings.Cut(limit, ":")
+		if !ok || !ok2 {
+			return fmt.Errorf("route limit %q is not PATTERN=RPS:BURST", entry)
+		}
+		rpsValue, err := strconv.ParseFloat(strings.TrimSpace(rps), 64)
+		if err != nil {
+			return fmt.Errorf("route limit %q has an invalid rps", entry)
+		}
+		burstValue, err := strconv.Atoi(strings.TrimSpace(burst))
+		if err != nil {
+			return fmt.Errorf("route limit %q has an invalid burst", entry)
+		}
+		limits[normalizePattern(pattern)] = RateLimit{RPS: rpsValue, Burst: burstValue}
+	}
+	*l = limits
+	return nil
+}
+
+func (l *RouteLimits) UnmarshalYAML(node *yaml.Node) error {
+	var limits map[string]RateLimit
+	if err := node.Decode(&limits); err != nil {
+		return err
+	}
+	*l = make(RouteLimits, len(limits))
+	for pattern, limit := range limits {
+		(*l)[normalizePattern(pattern)] = limit
+	}
+	return nil
+}
+
+func normalizePattern(pattern string) string {
+	return strings.Join(strings.Fields(pattern), " ")
+}
+
+// Prefixes is a list of CIDRs. Single addresses are accepted as host
+// prefixes. In the environment and on the command line the entries are
+// separated by commas.
+type Prefixes []netip.Prefix
+
+func (p *Prefixes) UnmarshalText(text []byte) error {
+	return p.set(strings.Split(string(text), ","))
+}
+
+func (p *Prefixes) UnmarshalYAML(node *yaml.Node) error {
+	var entries []string
+	if node.Kind == yaml.ScalarNode {
+		entries = strings.Split(node.Value, ",")
+	} else if err := node.Decode(&entries); err != nil {
+		return err
+	}
+	return p.set(entries)
+}
+
+func (p Prefixes) MarshalYAML() (any, error) {
+	entries := make([]string, len(p))
+	for i, prefix := range p {
+		entries[i] = prefix.String()
+	}
+	return entries, nil
+}
+
+func (p *Prefixes) set(entries []string) error {
+	prefixes := Prefixes{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return fmt.Errorf("%q is not an address or CIDR", entry)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	*p = prefixes
+	return nil
+}
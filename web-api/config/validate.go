@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/datasource"
+)
+
+// validate returns every problem of the configuration. Settings are named
+// by their YAML path.
+func (c *Config) validate() []string {
+	var v validator
+
+	v.check(c.Server.Port >= 1 && c.Server.Port <= 65535, "server.port must be between 1 and 65535")
+	v.check(c.Server.ReadHeaderTimeout > 0, "server.read_header_timeout must be positive")
+	v.check(c.Server.ReadTimeout >= 0, "server.read_timeout must not be negative")
+	v.check(c.Server.WriteTimeout >= 0, "server.write_timeout must not be negative")
+	v.check(c.Server.IdleTimeout > 0, "server.idle_timeout must be positive")
+	v.check(c.Server.MaxHeaderBytes >= 4096, "server.max_header_bytes must be at least 4096")
+	v.check((c.Server.TLSCertFile == "") == (c.Server.TLSKeyFile == ""), "server.tls_cert_file and server.tls_key_file must be set together")
+	v.check(c.Server.TLSReloadInterval > 0, "server.tls_reload_interval must be positive")
+	v.check(!c.Server.H2C || c.Server.TLSCertFile == "", "server.h2c cannot be combined with TLS")
+
+	if c.Admin.Enabled() {
+		_, port, err := net.SplitHostPort(c.Admin.Addr)
+		v.check(err == nil && port != "", "admin.addr must be host:port or off")
+	}
+
+	v.check(c.Database.URL != "" || !datasource.NeedsDatabase(c.Source.Users, c.Source.Posts), "database.url is required")
+	if c.Database.URL != "" {
+		scheme, _, _ := strings.Cut(c.Database.URL, "://")
+		v.check(slices.Contains([]string{"postgres", "postgresql", "mysql", "sqlite"}, scheme), "database.url must start with postgres://, postgresql://, mysql:// or sqlite://")
+	}
+	v.check(c.Database.MaxOpenConns >= 0, "database.max_open_conns must not be negative")
+	v.check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns must not be negative")
+	v.check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns, "database.max_idle_conns must not exceed database.max_open_conns")
+	v.check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime must not be negative")
+	v.check(c.Database.ConnMaxIdleTime >= 0, "database.conn_max_idle_time must not be negative")
+	v.oneOf("database.log_level", c.Database.LogLevel, "silent", "error", "warn", "info")
+	v.check(c.Database.SlowQueryThreshold >= 0, "database.slow_query_threshold must not be negative")
+
+	v.oneOf("seed.profile", c.Seed.Profile, "none", "demo", "load-test")
+	v.check(c.Seed.Users >= 0, "seed.users must not be negative")
+	v.check(c.Seed.PostsPerUser >= 0, "seed.posts_per_user must not be negative")
+
+	v.httpURL("gateway.jsonplaceholder_url", c.Gateway.JSONPlaceholderURL)
+	v.check(c.Gateway.Timeout > 0, "gateway.timeout must be positive")
+
+	v.check(c.Sync.Interval >= 0, "sync.interval must not be negative")
+	v.oneOf("source.users", c.Source.Users, datasource.Postgres, datasource.JSONPlaceholder, datasource.Memory, datasource.Fallback)
+	v.oneOf("source.posts", c.Source.Posts, datasource.Postgres, datasource.JSONPlaceholder, datasource.Memory, datasource.Fallback)
+	v.oneOf("fallback.users", c.Fallback.Users, "outage", "miss")
+	v.oneOf("fallback.posts", c.Fallback.Posts, "outage", "miss")
+
+	var level slog.Level
+	v.check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "log.level must be one of debug, info, warn or error")
+
+	v.check(c.JWT.ClockSkew >= 0, "jwt.clock_skew must not be negative")
+	v.check(c.JWT.KeysRefresh > 0, "jwt.keys_refresh_interval must be positive")
+	v.check(c.JWT.AccessTokenTTL > 0, "jwt.access_token_ttl must be positive")
+	v.check(c.JWT.RefreshTokenTTL > c.JWT.AccessTokenTTL, "jwt.refresh_token_ttl must exceed jwt.access_token_ttl")
+
+	v.check(c.Password.Parallelism >= 1 && c.Password.Parallelism <= 255, "password.argon2_parallelism must be between 1 and 255")
+	v.check(c.Password.Iterations >= 1, "password.argon2_iterations must be at least 1")
+	v.check(c.Password.MemoryKiB >= 8*c.Password.Parallelism && c.Password.MemoryKiB >= 8192, "password.argon2_memory_kib must be at least 8192 and 8 per lane")
+
+	if c.OIDC.Enabled() {
+		v.httpURL("oidc.issuer_url", c.OIDC.IssuerURL)
+		v.httpURL("oidc.redirect_url", c.OIDC.RedirectURL)
+		v.check(c.OIDC.ClientID != "", "oidc.client_id is required with oidc.issuer_url")
+		v.check(slices.Contains(c.OIDC.Scopes, "openid"), "oidc.scopes must include openid")
+		v.check(c.JWT.SigningKeysPath != "", "oidc.issuer_url requires jwt.signing_keys_path")
+	}
+
+	v.check(c.RateLimit.IPRPS >= 0, "rate_limit.ip_rps must not be negative")
+	v.check(c.RateLimit.IPRPS == 0 || c.RateLimit.IPBurst >= 1, "rate_limit.ip_burst must be at least 1")
+	v.check(c.RateLimit.RPS >= 0, "rate_limit.rps must not be negative")
+	v.check(c.RateLimit.RPS == 0 || c.RateLimit.Burst >= 1, "rate_limit.burst must be at least 1")
+	for pattern, limit := range c.RateLimit.Routes {
+		v.check(pattern != "", "rate_limit.routes has an empty pattern")
+		v.check(limit.RPS >= 0, fmt.Sprintf("rate_limit.routes[%q].rps must not be negative", pattern))
+		v.check(limit.RPS == 0 || limit.Burst >= 1, fmt.Sprintf("rate_limit.routes[%q].burst must be at least 1", pattern))
+	}
+	v.check(c.RateLimit.IdleTTL > 0, "rate_limit.idle_ttl must be positive")
+
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, "none", "stdout", "otlp")
+	v.check(c.Tracing.ServiceName != "", "tracing.service_name is required")
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+
+	v.check(c.Health.CheckTimeout > 0, "health.check_timeout must be positive")
+	v.check(c.Health.CacheTTL >= 0, "health.cache_ttl must not be negative")
+
+	v.check(c.Shutdown.DrainDelay >= 0, "shutdown.drain_delay must not be negative")
+	v.check(c.Shutdown.Timeout > 0, "shutdown.timeout must be positive")
+	v.check(c.Shutdown.CloseTimeout > 0, "shutdown.close_timeout must be positive")
+
+	return v.problems
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) check(ok bool, problem string) {
+	if !ok {
+		v.problems = append(v.problems, problem)
+	}
+}
+
+func (v *validator) oneOf(name, value string, allowed ...string) {
+	v.check(slices.Contains(allowed, value), fmt.Sprintf("%s must be one of %s", name, strings.Join(allowed, ", ")))
+}
+
+func (v *validator) httpURL(name, value string) {
+	u, err := url.Parse(value)
+	v.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", name+" must be an absolute http or https URL")
+}
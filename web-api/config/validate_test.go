@@ -0,0 +1,122 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid := func() *Config {
+		cfg := Default()
+		cfg.Database.URL = "postgres://app@db/api"
+		return cfg
+	}
+	if problems := valid().validate(); len(problems) != 0 {
+		t.Fatalf("defaults with a database have problems: %q", problems)
+	}
+	if problems := Dev().validate(); len(problems) != 0 {
+		t.Fatalf("dev defaults have problems: %q", problems)
+	}
+
+	tests := []struct {
+		name   string
+		change func(*Config)
+		want   []string
+	}{
+		{
+			name:   "database required",
+			change: func(c *Config) { c.Database.URL = "" },
+			want:   []string{"database.url is required"},
+		},
+		{
+			name: "database not required in memory",
+			change: func(c *Config) {
+				c.Database.URL = ""
+				c.Source = SourceConfig{Users: "memory", Posts: "jsonplaceholder"}
+			},
+		},
+		{
+			name:   "database scheme",
+			change: func(c *Config) { c.Database.URL = "oracle://db" },
+			want:   []string{"database.url must start with postgres://, postgresql://, mysql:// or sqlite://"},
+		},
+		{
+			name:   "port",
+			change: func(c *Config) { c.Server.Port = 70000 },
+			want:   []string{"server.port must be between 1 and 65535"},
+		},
+		{
+			name:   "TLS pair",
+			change: func(c *Config) { c.Server.TLSCertFile = "cert.pem" },
+			want:   []string{"server.tls_cert_file and server.tls_key_file must be set together"},
+		},
+		{
+			name:   "admin address",
+			change: func(c *Config) { c.Admin.Addr = "localhost" },
+			want:   []string{"admin.addr must be host:port or off"},
+		},
+		{
+			name:   "admin off",
+			change: func(c *Config) { c.Admin.Addr = "off" },
+		},
+		{
+			name:   "idle connections",
+			change: func(c *Config) { c.Database.MaxOpenConns = 5; c.Database.MaxIdleConns = 10 },
+			want:   []string{"database.max_idle_conns must not exceed database.max_open_conns"},
+		},
+		{
+			name:   "source",
+			change: func(c *Config) { c.Source.Posts = "redis" },
+			want:   []string{"source.posts must be one of postgres, jsonplaceholder, memory, fallback"},
+		},
+		{
+			name:   "token lifetimes",
+			change: func(c *Config) { c.JWT.RefreshTokenTTL = c.JWT.AccessTokenTTL },
+			want:   []string{"jwt.refresh_token_ttl must exceed jwt.access_token_ttl"},
+		},
+		{
+			name: "OIDC",
+			change: func(c *Config) {
+				c.OIDC.IssuerURL = "https://idp.example.com"
+				c.OIDC.RedirectURL = "/callback"
+				c.OIDC.Scopes = []string{"email"}
+			},
+			want: []string{
+				"oidc.redirect_url must be an absolute http or https URL",
+				"oidc.client_id is required with oidc.issuer_url",
+				"oidc.scopes must include openid",
+				"oidc.issuer_url requires jwt.signing_keys_path",
+			},
+		},
+		{
+			name: "route limit",
+			change: func(c *Config) {
+				c.RateLimit.Routes = RouteLimits{"POST /auth/login": {RPS: 1}}
+			},
+			want: []string{`rate_limit.routes["POST /auth/login"].burst must be at least 1`},
+		},
+		{
+			name: "several sections",
+			change: func(c *Config) {
+				c.Server.MaxHeaderBytes = 1024
+				c.Tracing.SampleRatio = 2
+				c.Shutdown.Timeout = 0
+			},
+			want: []string{
+				"server.max_header_bytes must be at least 4096",
+				"tracing.sample_ratio must be between 0 and 1",
+				"shutdown.timeout must be positive",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.change(cfg)
+			if problems := cfg.validate(); !slices.Equal(problems, tt.want) {
+				t.Errorf("problems = %q, want %q", problems, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+// Methods by which a principal authenticated.
+const (
+	MethodJWT    = "jwt"
+	MethodAPIKey = "apikey"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	subject string
+	userID  valueobject.UserID
+	role    valueobject.Role
+	method  string
+	scopes  []string
+}
+
+func NewPrincipal(subject string, userID valueobject.UserID, role valueobject.Role, method string, scopes []string) *Principal {
+	return &Principal{
+		subject: subject,
+		userID:  userID,
+		role:    role,
+		method:  method,
+		scopes:  scopes,
+	}
+}
+
+func (p *Principal) Subject() string {
+	return p.subject
+}
+
+// UserID returns the local user the principal acts as. It is the zero value
+// when the subject is not a local user.
+func (p *Principal) UserID() valueobject.UserID {
+	return p.userID
+}
+
+// Role returns the zero value for principals without a role, which is
+// granted nothing.
+func (p *Principal) Role() valueobject.Role {
+	return p.role
+}
+
+func (p *Principal) Method() string {
+	return p.method
+}
+
+func (p *Principal) Scopes() []string {
+	return p.scopes
+}
+
+// HasScope reports whether the principal was granted scope. A principal
+// without any scopes is unrestricted.
+func (p *Principal) HasScope(scope string) bool {
+	if len(p.scopes) == 0 {
+		return true
+	}
+	for _, s := range p.scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, or nil for
+// anonymous requests.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
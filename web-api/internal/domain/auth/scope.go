@@ -0,0 +1,16 @@
+package auth
+
+// Scopes that can be granted to API keys.
+const (
+	ScopePostsRead  = "posts:read"
+	ScopePostsWrite = "posts:write"
+	ScopeUsersRead  = "users:read"
+)
+
+func IsValidScope(scope string) bool {
+	switch scope {
+	case ScopePostsRead, ScopePostsWrite, ScopeUsersRead:
+		return true
+	}
+	return false
+}
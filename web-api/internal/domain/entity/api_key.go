@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+// APIKey is a long-lived credential for non-interactive clients. Only a hash
+// of the secret is stored; the ID is public and is embedded in the key so it
+// can be looked up.
+type APIKey struct {
+	id         string
+	userID     valueobject.UserID
+	name       string
+	keyHash    string
+	scopes     []string
+	expiresAt  time.Time
+	lastUsedAt time.Time
+	createdAt  time.Time
+}
+
+func NewAPIKey(id string, userID valueobject.UserID, name, keyHash string, scopes []string, expiresAt, lastUsedAt, createdAt time.Time) *APIKey {
+	return &APIKey{
+		id:         id,
+		userID:     userID,
+		name:       name,
+		keyHash:    keyHash,
+		scopes:     scopes,
+		expiresAt:  expiresAt,
+		lastUsedAt: lastUsedAt,
+		createdAt:  createdAt,
+	}
+}
+
+func (k *APIKey) ID() string {
+	return k.id
+}
+
+func (k *APIKey) UserID() valueobject.UserID {
+	return k.userID
+}
+
+func (k *APIKey) Name() string {
+	return k.name
+}
+
+func (k *APIKey) KeyHash() string {
+	return k.keyHash
+}
+
+func (k *APIKey) Scopes() []string {
+	return k.scopes
+}
+
+// ExpiresAt returns the zero time for keys that never expire.
+func (k *APIKey) ExpiresAt() time.Time {
+	return k.expiresAt
+}
+
+// LastUsedAt returns the zero time for keys that were never used.
+func (k *APIKey) LastUsedAt() time.Time {
+	return k.lastUsedAt
+}
+
+func (k *APIKey) CreatedAt() time.Time {
+	return k.createdAt
+}
+
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return !k.expiresAt.IsZero() && !now.Before(k.expiresAt)
+}
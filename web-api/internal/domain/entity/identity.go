@@ -0,0 +1,31 @@
+package entity
+
+import "github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+
+// Identity links an account at an external identity provider, identified by
+// the provider's issuer and subject, to a local user.
+type Identity struct {
+	issuer  string
+	subject string
+	userID  valueobject.UserID
+}
+
+func NewIdentity(issuer, subject string, userID valueobject.UserID) *Identity {
+	return &Identity{
+		issuer:  issuer,
+		subject: subject,
+		userID:  userID,
+	}
+}
+
+func (i *Identity) Issuer() string {
+	return i.issuer
+}
+
+func (i *Identity) Subject() string {
+	return i.subject
+}
+
+func (i *Identity) UserID() valueobject.UserID {
+	return i.userID
+}
@@ -7,6 +7,8 @@ type Post struct {
 	userID valueobject.UserID
 	title  string
 	body   string
+	// readOnly marks a post read from an external service.
+	readOnly bool
 }
 
 func NewPost(id valueobject.PostID, userID valueobject.UserID, title, body string) *Post {
@@ -32,4 +34,14 @@ func (p *Post) Title() string {
 
 func (p *Post) Body() string {
 	return p.body
+}
+
+// ReadOnly reports that the post was read from an external service. Its
+// IDs are the IDs there, so it must not be written to the local store.
+func (p *Post) ReadOnly() bool {
+	return p.readOnly
+}
+
+func (p *Post) MarkReadOnly() {
+	p.readOnly = true
 }
\ No newline at end of file
@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+// RefreshToken is a single-use token that can be exchanged for a new access
+// token. Tokens created by rotating one another share a family so that the
+// whole chain can be revoked when reuse of an old token is detected.
+type RefreshToken struct {
+	id        string
+	userID    valueobject.UserID
+	familyID  string
+	tokenHash string
+	expiresAt time.Time
+	revokedAt time.Time
+}
+
+func NewRefreshToken(id string, userID valueobject.UserID, familyID, tokenHash string, expiresAt, revokedAt time.Time) *RefreshToken {
+	return &RefreshToken{
+		id:        id,
+		userID:    userID,
+		familyID:  familyID,
+		tokenHash: tokenHash,
+		expiresAt: expiresAt,
+		revokedAt: revokedAt,
+	}
+}
+
+func (t *RefreshToken) ID() string {
+	return t.id
+}
+
+func (t *RefreshToken) UserID() valueobject.UserID {
+	return t.userID
+}
+
+func (t *RefreshToken) FamilyID() string {
+	return t.familyID
+}
+
+func (t *RefreshToken) TokenHash() string {
+	return t.tokenHash
+}
+
+func (t *RefreshToken) ExpiresAt() time.Time {
+	return t.expiresAt
+}
+
+// RevokedAt returns the zero time while the token is still usable.
+func (t *RefreshToken) RevokedAt() time.Time {
+	return t.revokedAt
+}
+
+func (t *RefreshToken) IsRevoked() bool {
+	return !t.revokedAt.IsZero()
+}
+
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.expiresAt)
+}
@@ -3,10 +3,14 @@ package entity
 import "github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
 
 type User struct {
-	id       valueobject.UserID
-	name     string
-	username string
-	email    valueobject.Email
+	id           valueobject.UserID
+	name         string
+	username     string
+	email        valueobject.Email
+	role         valueobject.Role
+	passwordHash string
+	// readOnly marks a user read from an external service.
+	readOnly bool
 }
 
 func NewUser(id valueobject.UserID, name, username string, email valueobject.Email) *User {
@@ -15,6 +19,7 @@ func NewUser(id valueobject.UserID, name, username string, email valueobject.Ema
 		name:     name,
 		username: username,
 		email:    email,
+		role:     valueobject.RoleUser,
 	}
 }
 
@@ -32,4 +37,32 @@ func (u *User) Username() string {
 
 func (u *User) Email() valueobject.Email {
 	return u.email
-}
\ No newline at end of file
+}
+
+func (u *User) Role() valueobject.Role {
+	return u.role
+}
+
+func (u *User) SetRole(role valueobject.Role) {
+	u.role = role
+}
+
+// PasswordHash returns the encoded password hash, or an empty string for
+// users that cannot sign in with a password.
+func (u *User) PasswordHash() string {
+	return u.passwordHash
+}
+
+func (u *User) SetPasswordHash(hash string) {
+	u.passwordHash = hash
+}
+
+// ReadOnly reports that the user was read from an external service. Its ID
+// is the ID there, so it must not be written to the local store.
+func (u *User) ReadOnly() bool {
+	return u.readOnly
+}
+
+func (u *User) MarkReadOnly() {
+	u.readOnly = true
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+type APIKeyRepository interface {
+	FindByID(ctx context.Context, id string) (*entity.APIKey, error)
+	FindByUserID(ctx context.Context, userID valueobject.UserID) ([]*entity.APIKey, error)
+	Save(ctx context.Context, key *entity.APIKey) error
+	TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error
+	Delete(ctx context.Context, id string) error
+}
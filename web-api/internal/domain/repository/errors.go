@@ -0,0 +1,8 @@
+package repository
+
+import "errors"
+
+// ErrDuplicate is returned by writes that would break a uniqueness rule,
+// such as a username or email that is already taken. Callers that checked
+// beforehand still get it when a concurrent write got there first.
+var ErrDuplicate = errors.New("duplicate key")
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+)
+
+type IdentityRepository interface {
+	FindBySubject(ctx context.Context, issuer, subject string) (*entity.Identity, error)
+	Save(ctx context.Context, identity *entity.Identity) error
+}
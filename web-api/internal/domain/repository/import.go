@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+// ImportCounts tells how many records an import created, changed or found
+// already up to date.
+type ImportCounts struct {
+	Created   int
+	Updated   int
+	Unchanged int
+}
+
+type ImportResult struct {
+	Users ImportCounts
+	Posts ImportCounts
+}
+
+// ImportRepository stores users and posts of an external service. The IDs
+// of the entities are the IDs in that service; records are matched by them,
+// not by local ID.
+type ImportRepository interface {
+	// Import creates or updates all records in one transaction. With dryRun
+	// the transaction is rolled back, so the result tells what would change.
+	Import(ctx context.Context, users []*entity.User, posts []*entity.Post, dryRun bool) (*ImportResult, error)
+	// FindUserByExternalID and FindPostByExternalID return the local record
+	// imported from the external ID, or nil if there is none.
+	FindUserByExternalID(ctx context.Context, id valueobject.UserID) (*entity.User, error)
+	FindPostByExternalID(ctx context.Context, id valueobject.PostID) (*entity.Post, error)
+}
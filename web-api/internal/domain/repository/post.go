@@ -11,4 +11,8 @@ type PostRepository interface {
 	FindAll(ctx context.Context) ([]*entity.Post, error)
 	FindByID(ctx context.Context, id valueobject.PostID) (*entity.Post, error)
 	FindByUserID(ctx context.Context, userID valueobject.UserID) ([]*entity.Post, error)
+	// Save stores a new post and returns it with its assigned ID.
+	Save(ctx context.Context, post *entity.Post) (*entity.Post, error)
+	Update(ctx context.Context, post *entity.Post) error
+	Delete(ctx context.Context, id valueobject.PostID) error
 }
\ No newline at end of file
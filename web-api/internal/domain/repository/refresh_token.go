@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+type RefreshTokenRepository interface {
+	FindByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	Save(ctx context.Context, token *entity.RefreshToken) error
+	// Revoke revokes a single token. It reports false if the token was
+	// already revoked, which lets callers detect concurrent reuse.
+	Revoke(ctx context.Context, id string) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID valueobject.UserID) error
+}
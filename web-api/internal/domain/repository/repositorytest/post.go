@@ -0,0 +1,208 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+// RunPostRepository runs the PostRepository suite. Posts are written by
+// users added through the same Subject.
+func RunPostRepository(t *testing.T, newSubject func(t *testing.T) Subject) {
+	ctx := context.Background()
+
+	t.Run("FindByIDMissing", func(t *testing.T) {
+		s := newSubject(t)
+		alice := s.addUser(t, "Alice", "alice", "alice@example.com")
+		s.addPost(t, alice, "Hello", "First post")
+
+		post, err := s.Posts.FindByID(ctx, postID(t, 9999))
+		if err != nil || post != nil {
+			t.Fatalf("FindByID = %v, %v; want nil, nil", post, err)
+		}
+	})
+
+	t.Run("FindAllEmpty", func(t *testing.T) {
+		s := newSubject(t)
+
+		posts, err := s.Posts.FindAll(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if posts == nil || len(posts) != 0 {
+			t.Fatalf("FindAll = %#v; want an empty, non-nil slice", posts)
+		}
+	})
+
+	t.Run("FindAllOrderedByID", func(t *testing.T) {
+		s := newSubject(t)
+		alice := s.addUser(t, "Alice", "alice", "alice@example.com")
+		bob := s.addUser(t, "Bob", "bob", "bob@example.com")
+		want := []*entity.Post{
+			s.addPost(t, bob, "Zebra", "z"),
+			s.addPost(t, alice, "Apple", "a"),
+			s.addPost(t, bob, "Mango", "m"),
+		}
+
+		posts, err := s.Posts.FindAll(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkPosts(t, posts, want)
+	})
+
+	t.Run("FindByID", func(t *testing.T) {
+		s := newSubject(t)
+		alice := s.addUser(t, "Alice", "alice", "alice@example.com")
+		s.addPost(t, alice, "Hello", "First post")
+		want := s.addPost(t, alice, "Again", "Second post")
+
+		post, err := s.Posts.FindByID(ctx, want.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkPost(t, post, want)
+	})
+
+	t.Run("FindByUserID", func(t *testing.T) {
+		s := newSubject(t)
+		alice := s.addUser(t, "Alice", "alice", "alice@example.com")
+		bob := s.addUser(t, "Bob", "bob", "bob@example.com")
+		first := s.addPost(t, bob, "Zebra", "z")
+		s.addPost(t, alice, "Apple", "a")
+		second := s.addPost(t, bob, "Mango", "m")
+
+		posts, err := s.Posts.FindByUserID(ctx, bob.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkPosts(t, posts, []*entity.Post{first, second})
+	})
+
+	t.Run("FindByUserIDWithoutPosts", func(t *testing.T) {
+		s := newSubject(t)
+		alice := s.addUser(t, "Alice", "alice", "alice@example.com")
+		bob := s.addUser(t, "Bob", "bob", "bob@example.com")
+		s.addPost(t, alice, "Apple", "a")
+
+		posts, err := s.Posts.FindByUserID(ctx, bob.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if posts == nil || len(posts) != 0 {
+			t.Fatalf("FindByUserID = %#v; want an empty, non-nil slice", posts)
+		}
+	})
+
+	t.Run("SaveAssignsIDs", func(t *testing.T) {
+		s := newSubject(t)
+		s.skipIfReadOnly(t)
+		alice := s.addUser(t, "Alice", "alice", "alice@example.com")
+
+		first := s.addPost(t, alice, "Hello", "First post")
+		second := s.addPost(t, alice, "Hello", "Same title")
+		if first.ID().Value() <= 0 || second.ID().Value() <= 0 || first.ID() == second.ID() {
+			t.Fatalf("Save assigned IDs %s and %s; want distinct positive IDs", first.ID(), second.ID())
+		}
+	})
+
+	t.Run("SaveRejectsUnknownAuthor", func(t *testing.T) {
+		s := newSubject(t)
+		s.skipIfReadOnly(t)
+
+		post := entity.NewPost(valueobject.PostID{}, userID(t, 9999), "Orphan", "No author")
+		if _, err := s.Posts.Save(ctx, post); err == nil {
+			t.Fatal("Save accepted a post by an unknown user")
+		}
+		if posts, err := s.Posts.FindAll(ctx); err != nil || len(posts) != 0 {
+			t.Fatalf("FindAll after the rejected Save = %v, %v; want no posts", posts, err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		s := newSubject(t)
+		s.skipIfReadOnly(t)
+		alice := s.addUser(t, "Alice", "alice", "alice@example.com")
+		bob := s.addUser(t, "Bob", "bob", "bob@example.com")
+		post := s.addPost(t, alice, "Hello", "First post")
+
+		want := entity.NewPost(post.ID(), bob.ID(), "Hello again", "Edited")
+		if err := s.Posts.Update(ctx, want); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := s.Posts.FindByID(ctx, post.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkPost(t, got, want)
+	})
+
+	t.Run("UpdateMissing", func(t *testing.T) {
+		s := newSubject(t)
+		s.skipIfReadOnly(t)
+		alice := s.addUser(t, "Alice", "alice", "alice@example.com")
+
+		missing := entity.NewPost(postID(t, 9999), alice.ID(), "Ghost", "")
+		if err := s.Posts.Update(ctx, missing); err != nil {
+			t.Fatalf("Update of a missing post = %v; want nil", err)
+		}
+		if post, err := s.Posts.FindByID(ctx, missing.ID()); err != nil || post != nil {
+			t.Fatalf("Update created the missing post: %v, %v", post, err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		s := newSubject(t)
+		s.skipIfReadOnly(t)
+		alice := s.addUser(t, "Alice", "alice", "alice@example.com")
+		deleted := s.addPost(t, alice, "Hello", "First post")
+		kept := s.addPost(t, alice, "Again", "Second post")
+
+		if err := s.Posts.Delete(ctx, deleted.ID()); err != nil {
+			t.Fatal(err)
+		}
+
+		posts, err := s.Posts.FindAll(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkPosts(t, posts, []*entity.Post{kept})
+	})
+
+	t.Run("DeleteMissing", func(t *testing.T) {
+		s := newSubject(t)
+		s.skipIfReadOnly(t)
+
+		if err := s.Posts.Delete(ctx, postID(t, 9999)); err != nil {
+			t.Fatalf("Delete of a missing post = %v; want nil", err)
+		}
+	})
+}
+
+func checkPost(t *testing.T, got, want *entity.Post) {
+	t.Helper()
+
+	if got == nil {
+		t.Fatalf("got no post; want %q (%s)", want.Title(), want.ID())
+	}
+	if got.ID() != want.ID() || got.UserID() != want.UserID() || got.Title() != want.Title() || got.Body() != want.Body() {
+		t.Errorf("got post {%s %s %q %q}; want {%s %s %q %q}",
+			got.ID(), got.UserID(), got.Title(), got.Body(),
+			want.ID(), want.UserID(), want.Title(), want.Body())
+	}
+}
+
+// checkPosts compares posts in order.
+func checkPosts(t *testing.T, got, want []*entity.Post) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d posts; want %d", len(got), len(want))
+	}
+	for i := range got {
+		checkPost(t, got[i], want[i])
+	}
+}
@@ -0,0 +1,219 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+// RunUserRepository runs the UserRepository suite.
+func RunUserRepository(t *testing.T, newSubject func(t *testing.T) Subject) {
+	ctx := context.Background()
+
+	t.Run("FindByIDMissing", func(t *testing.T) {
+		s := newSubject(t)
+		s.addUser(t, "Alice", "alice", "alice@example.com")
+
+		user, err := s.Users.FindByID(ctx, userID(t, 9999))
+		if err != nil || user != nil {
+			t.Fatalf("FindByID = %v, %v; want nil, nil", user, err)
+		}
+	})
+
+	t.Run("FindByEmailMissing", func(t *testing.T) {
+		s := newSubject(t)
+		s.addUser(t, "Alice", "alice", "alice@example.com")
+
+		email, _ := valueobject.NewEmail("bob@example.com")
+		user, err := s.Users.FindByEmail(ctx, email)
+		if err != nil || user != nil {
+			t.Fatalf("FindByEmail = %v, %v; want nil, nil", user, err)
+		}
+	})
+
+	t.Run("FindByUsernameMissing", func(t *testing.T) {
+		s := newSubject(t)
+		s.addUser(t, "Alice", "alice", "alice@example.com")
+
+		user, err := s.Users.FindByUsername(ctx, "bob")
+		if err != nil || user != nil {
+			t.Fatalf("FindByUsername = %v, %v; want nil, nil", user, err)
+		}
+	})
+
+	t.Run("FindAllEmpty", func(t *testing.T) {
+		s := newSubject(t)
+
+		users, err := s.Users.FindAll(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if users == nil || len(users) != 0 {
+			t.Fatalf("FindAll = %#v; want an empty, non-nil slice", users)
+		}
+	})
+
+	t.Run("FindAllOrderedByID", func(t *testing.T) {
+		s := newSubject(t)
+		want := []*entity.User{
+			s.addUser(t, "Carol", "carol", "carol@example.com"),
+			s.addUser(t, "Alice", "alice", "alice@example.com"),
+			s.addUser(t, "Bob", "bob", "bob@example.com"),
+		}
+
+		users, err := s.Users.FindAll(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(users) != len(want) {
+			t.Fatalf("FindAll returned %d users; want %d", len(users), len(want))
+		}
+		for i := range users {
+			checkUser(t, users[i], want[i])
+			if i > 0 && users[i].ID().Value() <= users[i-1].ID().Value() {
+				t.Errorf("FindAll is not ordered by ID: %s after %s", users[i].ID(), users[i-1].ID())
+			}
+		}
+	})
+
+	t.Run("FindByID", func(t *testing.T) {
+		s := newSubject(t)
+		s.addUser(t, "Alice", "alice", "alice@example.com")
+		want := s.addUser(t, "Bob", "bob", "bob@example.com")
+
+		user, err := s.Users.FindByID(ctx, want.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkUser(t, user, want)
+	})
+
+	t.Run("FindByEmail", func(t *testing.T) {
+		s := newSubject(t)
+		s.addUser(t, "Alice", "alice", "alice@example.com")
+		want := s.addUser(t, "Bob", "bob", "bob@example.com")
+
+		user, err := s.Users.FindByEmail(ctx, want.Email())
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkUser(t, user, want)
+	})
+
+	t.Run("FindByUsername", func(t *testing.T) {
+		s := newSubject(t)
+		s.addUser(t, "Alice", "alice", "alice@example.com")
+		want := s.addUser(t, "Bob", "bob", "bob@example.com")
+
+		user, err := s.Users.FindByUsername(ctx, "bob")
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkUser(t, user, want)
+	})
+
+	t.Run("SaveAssignsIDs", func(t *testing.T) {
+		s := newSubject(t)
+		s.skipIfReadOnly(t)
+
+		alice := s.addUser(t, "Alice", "alice", "alice@example.com")
+		bob := s.addUser(t, "Bob", "bob", "bob@example.com")
+		if alice.ID().Value() <= 0 || bob.ID().Value() <= 0 || alice.ID() == bob.ID() {
+			t.Fatalf("Save assigned IDs %s and %s; want distinct positive IDs", alice.ID(), bob.ID())
+		}
+	})
+
+	t.Run("SaveKeepsRoleAndPasswordHash", func(t *testing.T) {
+		s := newSubject(t)
+		s.skipIfReadOnly(t)
+
+		email, _ := valueobject.NewEmail("alice@example.com")
+		user := entity.NewUser(valueobject.UserID{}, "Alice", "alice", email)
+		user.SetRole(valueobject.RoleAdmin)
+		user.SetPasswordHash("hash")
+		saved, err := s.Users.Save(ctx, user)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		found, err := s.Users.FindByID(ctx, saved.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkUser(t, found, saved)
+		if found == nil || found.PasswordHash() != "hash" {
+			t.Errorf("password hash was not stored")
+		}
+	})
+
+	t.Run("SaveRejectsDuplicateUsername", func(t *testing.T) {
+		s := newSubject(t)
+		s.skipIfReadOnly(t)
+		s.addUser(t, "Alice", "alice", "alice@example.com")
+
+		email, _ := valueobject.NewEmail("other@example.com")
+		if _, err := s.Users.Save(ctx, entity.NewUser(valueobject.UserID{}, "Other", "alice", email)); !errors.Is(err, repository.ErrDuplicate) {
+			t.Fatalf("Save of a duplicate username = %v, want ErrDuplicate", err)
+		}
+	})
+
+	t.Run("SaveRejectsDuplicateEmail", func(t *testing.T) {
+		s := newSubject(t)
+		s.skipIfReadOnly(t)
+		alice := s.addUser(t, "Alice", "alice", "alice@example.com")
+
+		if _, err := s.Users.Save(ctx, entity.NewUser(valueobject.UserID{}, "Other", "other", alice.Email())); !errors.Is(err, repository.ErrDuplicate) {
+			t.Fatalf("Save of a duplicate email = %v, want ErrDuplicate", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		s := newSubject(t)
+		s.skipIfReadOnly(t)
+		alice := s.addUser(t, "Alice", "alice", "alice@example.com")
+
+		email, _ := valueobject.NewEmail("alice@example.org")
+		want := entity.NewUser(alice.ID(), "Alice Smith", "asmith", email)
+		want.SetRole(valueobject.RoleAdmin)
+		if err := s.Users.Update(ctx, want); err != nil {
+			t.Fatal(err)
+		}
+
+		user, err := s.Users.FindByID(ctx, alice.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkUser(t, user, want)
+	})
+
+	t.Run("UpdateMissing", func(t *testing.T) {
+		s := newSubject(t)
+		s.skipIfReadOnly(t)
+
+		email, _ := valueobject.NewEmail("ghost@example.com")
+		missing := entity.NewUser(userID(t, 9999), "Ghost", "ghost", email)
+		if err := s.Users.Update(ctx, missing); err != nil {
+			t.Fatalf("Update of a missing user = %v; want nil", err)
+		}
+		if user, err := s.Users.FindByID(ctx, missing.ID()); err != nil || user != nil {
+			t.Fatalf("Update created the missing user: %v, %v", user, err)
+		}
+	})
+}
+
+func checkUser(t *testing.T, got, want *entity.User) {
+	t.Helper()
+
+	if got == nil {
+		t.Fatalf("got no user; want %s (%s)", want.Username(), want.ID())
+	}
+	if got.ID() != want.ID() || got.Name() != want.Name() || got.Username() != want.Username() || got.Email() != want.Email() || got.Role() != want.Role() {
+		t.Errorf("got user {%s %q %q %s %s}; want {%s %q %q %s %s}",
+			got.ID(), got.Name(), got.Username(), got.Email(), got.Role(),
+			want.ID(), want.Name(), want.Username(), want.Email(), want.Role())
+	}
+}
@@ -11,4 +11,8 @@ type UserRepository interface {
 	FindAll(ctx context.Context) ([]*entity.User, error)
 	FindByID(ctx context.Context, id valueobject.UserID) (*entity.User, error)
 	FindByEmail(ctx context.Context, email valueobject.Email) (*entity.User, error)
-}
\ No newline at end of file
+	FindByUsername(ctx context.Context, username string) (*entity.User, error)
+	// Save stores a new user and returns it with its assigned ID.
+	Save(ctx context.Context, user *entity.User) (*entity.User, error)
+	Update(ctx context.Context, user *entity.User) error
+}
@@ -0,0 +1,37 @@
+package valueobject
+
+import "errors"
+
+// Role is a user's authorization level. Each role includes the permissions
+// of the roles below it: user < moderator < admin.
+type Role struct {
+	value string
+}
+
+var (
+	RoleUser      = Role{value: "user"}
+	RoleModerator = Role{value: "moderator"}
+	RoleAdmin     = Role{value: "admin"}
+)
+
+var roleRanks = map[string]int{
+	RoleUser.value:      1,
+	RoleModerator.value: 2,
+	RoleAdmin.value:     3,
+}
+
+func NewRole(value string) (Role, error) {
+	if _, ok := roleRanks[value]; !ok {
+		return Role{}, errors.New("invalid role")
+	}
+	return Role{value: value}, nil
+}
+
+func (r Role) String() string {
+	return r.value
+}
+
+// Includes reports whether r grants at least the permissions of other.
+func (r Role) Includes(other Role) bool {
+	return roleRanks[r.value] >= roleRanks[other.value]
+}
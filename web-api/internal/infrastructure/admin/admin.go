@@ -0,0 +1,127 @@
+// Package admin serves the operational endpoints of the admin listener:
+// metrics, pprof, readiness details, the runtime log level and build
+// information. The
+// listener binds to loopback by default and has no authentication, so it
+// must never be exposed publicly.
+package admin
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+const maxRequestBodySize = 1 << 10
+
+type Options struct {
+	Metrics http.Handler
+	// Readiness serves the readiness checks with their errors.
+	Readiness http.Handler
+	LogLevel  *slog.LevelVar
+	Version   string
+}
+
+func NewMux(opts Options) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	if opts.Metrics != nil {
+		mux.Handle("GET /metrics", opts.Metrics)
+	}
+	if opts.Readiness != nil {
+		mux.Handle("GET /readyz", opts.Readiness)
+	}
+
+	mux.HandleFunc("GET /debug/pprof/", pprof.Index)
+	mux.HandleFunc("GET /debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("GET /debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("GET /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("POST /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("GET /debug/pprof/trace", pprof.Trace)
+
+	if opts.LogLevel != nil {
+		mux.Handle("GET /log-level", getLogLevel(opts.LogLevel))
+		mux.Handle("PUT /log-level", setLogLevel(opts.LogLevel))
+	}
+
+	mux.Handle("GET /build-info", buildInfo(opts.Version))
+
+	return mux
+}
+
+type logLevel struct {
+	Level string `json:"level"`
+}
+
+func getLogLevel(level *slog.LevelVar) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, logLevel{Level: level.Level().String()})
+	})
+}
+
+// setLogLevel changes the level of the running process. The change is not
+// persisted and is lost on restart.
+func setLogLevel(level *slog.LevelVar) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req logLevel
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize)).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		var newLevel slog.Level
+		if err := newLevel.UnmarshalText([]byte(strings.TrimSpace(req.Level))); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "level must be one of debug, info, warn or error"})
+			return
+		}
+
+		old := level.Level()
+		level.Set(newLevel)
+		slog.Info("log level changed", "from", old.String(), "to", newLevel.String())
+
+		writeJSON(w, http.StatusOK, logLevel{Level: newLevel.String()})
+	})
+}
+
+type buildInfoResponse struct {
+	Version   string `json:"version"`
+	GoVersion string `json:"go_version"`
+	Module    string `json:"module,omitempty"`
+	Revision  string `json:"revision,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+}
+
+func buildInfo(version string) http.Handler {
+	resp := buildInfoResponse{
+		Version:   version,
+		GoVersion: runtime.Version(),
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		resp.Module = info.Main.Path
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				resp.Revision = setting.Value
+			case "vcs.time":
+				resp.BuildTime = setting.Value
+			case "vcs.modified":
+				resp.Modified = setting.Value == "true"
+			}
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,44 @@
+// Package databasetest opens migrated databases for tests.
+package databasetest
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database"
+)
+
+// Open connects to url, applies every migration and closes the connection
+// when the test ends.
+func Open(t testing.TB, url string) *gorm.DB {
+	t.Helper()
+
+	db, err := database.Connect(database.Options{URL: url, LogLevel: "silent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrator, err := database.NewMigrator(db, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// SQLite opens a new in-memory SQLite database.
+func SQLite(t testing.TB) *gorm.DB {
+	t.Helper()
+	return Open(t, "sqlite://:memory:")
+}
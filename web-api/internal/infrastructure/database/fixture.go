@@ -0,0 +1,150 @@
+package database
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+//go:embed fixtures
+var fixtureFiles embed.FS
+
+// fixtures are users and posts to seed, read from JSON or YAML. Posts refer
+// to their author by username, so fixtures do not depend on the IDs the
+// database assigns.
+type fixtures struct {
+	Users []userFixture `json:"users" yaml:"users"`
+	Posts []postFixture `json:"posts" yaml:"posts"`
+}
+
+type userFixture struct {
+	Username string `json:"username" yaml:"username"`
+	Name     string `json:"name" yaml:"name"`
+	Email    string `json:"email" yaml:"email"`
+	// Role defaults to user.
+	Role string `json:"role" yaml:"role"`
+}
+
+type postFixture struct {
+	Author string `json:"author" yaml:"author"`
+	Title  string `json:"title" yaml:"title"`
+	Body   string `json:"body" yaml:"body"`
+}
+
+func (f *fixtures) add(other fixtures) {
+	f.Users = append(f.Users, other.Users...)
+	f.Posts = append(f.Posts, other.Posts...)
+}
+
+// embeddedFixtures reads fixtures/<name>.yaml.
+func embeddedFixtures(name string) (fixtures, error) {
+	return readFixtures(fixtureFiles, "fixtures/"+name+".yaml")
+}
+
+// loadFixtures reads a fixture file, or every .json, .yaml and .yml file of
+// a directory in name order.
+func loadFixtures(path string) (fixtures, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fixtures{}, err
+	}
+	if !info.IsDir() {
+		return readFixtures(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fixtures{}, err
+	}
+	var all fixtures
+	for _, entry := range entries {
+		if entry.IsDir() || !isFixtureFile(entry.Name()) {
+			continue
+		}
+		f, err := readFixtures(os.DirFS(path), entry.Name())
+		if err != nil {
+			return fixtures{}, err
+		}
+		all.add(f)
+	}
+	return all, nil
+}
+
+func isFixtureFile(name string) bool {
+	return slices.Contains([]string{".json", ".yaml", ".yml"}, strings.ToLower(filepath.Ext(name)))
+}
+
+func readFixtures(fsys fs.FS, name string) (fixtures, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return fixtures{}, err
+	}
+
+	var f fixtures
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&f)
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&f)
+	default:
+		err = fmt.Errorf("unsupported file type, use .json, .yaml or .yml")
+	}
+	if err != nil {
+		return fixtures{}, fmt.Errorf("fixtures %s: %w", name, err)
+	}
+	return f, nil
+}
+
+// validate checks every fixture. Authors are resolved when seeding, since
+// posts may refer to users that are already in the database.
+func (f fixtures) validate() error {
+	var problems []string
+	usernames := make(map[string]bool)
+	emails := make(map[string]bool)
+
+	for i, u := range f.Users {
+		switch {
+		case u.Username == "" || u.Name == "":
+			problems = append(problems, fmt.Sprintf("user %d: username and name are required", i+1))
+		case usernames[u.Username]:
+			problems = append(problems, fmt.Sprintf("user %s: duplicate username", u.Username))
+		}
+		if _, err := valueobject.NewEmail(u.Email); err != nil {
+			problems = append(problems, fmt.Sprintf("user %s: %v", u.Username, err))
+		} else if emails[strings.ToLower(u.Email)] {
+			problems = append(problems, fmt.Sprintf("user %s: duplicate email %s", u.Username, u.Email))
+		}
+		if u.Role != "" {
+			if _, err := valueobject.NewRole(u.Role); err != nil {
+				problems = append(problems, fmt.Sprintf("user %s: invalid role %q", u.Username, u.Role))
+			}
+		}
+		usernames[u.Username] = true
+		emails[strings.ToLower(u.Email)] = true
+	}
+
+	for i, p := range f.Posts {
+		if p.Author == "" || strings.TrimSpace(p.Title) == "" {
+			problems = append(problems, fmt.Sprintf("post %d: author and title are required", i+1))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid fixtures: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+var (
+	firstNames = []string{
+		"Aiko", "Alex", "Amara", "Ben", "Carlos", "Chloe", "Daniel", "Elena",
+		"Emma", "Farah", "Hana", "Hiroshi", "Isabel", "James", "Kenji", "Leila",
+		"Liam", "Lucas", "Maya", "Mei", "Noah", "Olivia", "Omar", "Priya",
+		"Ravi", "Sakura", "Sofia", "Takumi", "Yuki", "Zoe",
+	}
+	lastNames = []string{
+		"Anderson", "Brown", "Chen", "Davis", "Garcia", "Hayashi", "Ito",
+		"Johnson", "Khan", "Kim", "Kobayashi", "Lee", "Lopez", "Martin",
+		"Moore", "Nakamura", "Nguyen", "Patel", "Rossi", "Sato", "Silva",
+		"Smith", "Suzuki", "Takahashi", "Tanaka", "Taylor", "Watanabe",
+		"Williams", "Wilson", "Yamamoto",
+	}
+	topics = []string{
+		"Go", "PostgreSQL", "Kubernetes", "API design", "testing", "caching",
+		"observability", "rate limiting", "authentication", "migrations",
+		"concurrency", "error handling", "code review", "deployments",
+	}
+	titleTemplates = []string{
+		"Notes on %s",
+		"What I learned about %s",
+		"A practical guide to %s",
+		"%s in production",
+		"Common mistakes with %s",
+		"Why %s matters",
+	}
+	sentences = []string{
+		"We tried several approaches before settling on this one.",
+		"The first version was simple and that turned out to be a strength.",
+		"Measuring before optimizing saved us a lot of time.",
+		"Most of the complexity came from edge cases nobody had written down.",
+		"The documentation covers the basics but not the failure modes.",
+		"In the end the fix was a single line, found after a day of debugging.",
+		"Load testing revealed a bottleneck we had not expected.",
+		"It is worth revisiting these decisions as the system grows.",
+		"A small change in the defaults made a big difference.",
+		"Here is what we would do differently next time.",
+	}
+)
+
+// generateFixtures creates users and postsPerUser posts for each of them,
+// for performance testing. The data is derived from a fixed seed, so
+// running the generator again with the same counts yields the same users
+// and posts, and seeding stays idempotent.
+func generateFixtures(users, postsPerUser int) fixtures {
+	rng := rand.New(rand.NewPCG(1, 2))
+	f := fixtures{
+		Users: make([]userFixture, 0, users),
+		Posts: make([]postFixture, 0, users*postsPerUser),
+	}
+
+	for i := 1; i <= users; i++ {
+		first := firstNames[rng.IntN(len(firstNames))]
+		last := lastNames[rng.IntN(len(lastNames))]
+		username := fmt.Sprintf("%s.%s%d", strings.ToLower(first), strings.ToLower(last), i)
+		f.Users = append(f.Users, userFixture{
+			Username: username,
+			Name:     first + " " + last,
+			Email:    username + "@example.com",
+		})
+
+		for j := 1; j <= postsPerUser; j++ {
+			topic := topics[rng.IntN(len(topics))]
+			title := fmt.Sprintf(titleTemplates[rng.IntN(len(titleTemplates))], topic)
+			body := make([]string, 2+rng.IntN(4))
+			for k := range body {
+				body[k] = sentences[rng.IntN(len(sentences))]
+			}
+			f.Posts = append(f.Posts, postFixture{
+				Author: username,
+				// The number keeps titles unique per author, which is how
+				// existing posts are recognised.
+				Title: fmt.Sprintf("%s (#%d)", title, j),
+				Body:  strings.Join(body, " "),
+			})
+		}
+	}
+	return f
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+// setupLockKey identifies the PostgreSQL advisory lock held while migrating
+// or seeding, so that replicas starting together do the work only once.
+// MySQL names its locks instead.
+const (
+	setupLockKey  int64 = 0x7765626170690001
+	setupLockName       = "web-api:setup"
+)
+
+// mysqlLockTimeout bounds the wait for a MySQL named lock, in seconds. The
+// wait also ends with the context.
+const mysqlLockTimeout = 24 * 60 * 60
+
+// sessionLock holds the statements that take and release a lock belonging
+// to a database session.
+type sessionLock struct {
+	try, wait, release string
+	key                any
+}
+
+var setupLocks = map[string]sessionLock{
+	postgresDialect: {
+		try:     "SELECT pg_try_advisory_lock($1)",
+		wait:    "SELECT true FROM pg_advisory_lock($1)",
+		release: "SELECT pg_advisory_unlock($1)",
+		key:     setupLockKey,
+	},
+	mysqlDialect: {
+		try:     "SELECT GET_LOCK(?, 0) = 1",
+		wait:    fmt.Sprintf("SELECT GET_LOCK(?, %d) = 1", mysqlLockTimeout),
+		release: "SELECT RELEASE_LOCK(?)",
+		key:     setupLockName,
+	},
+}
+
+// withSetupLock runs fn while holding the setup lock, waiting for any other
+// instance that holds it. The lock belongs to a database session, so fn gets
+// a handle bound to the connection holding it; the lock is released when fn
+// returns or the connection is lost.
+//
+// SQLite has no such locks. A SQLite database is a local file served by a
+// single instance, whose one connection already serializes the work, so fn
+// runs without a lock.
+func withSetupLock(ctx context.Context, db *gorm.DB, logger *slog.Logger, fn func(db *gorm.DB) error) error {
+	lock, ok := setupLocks[db.Dialector.Name()]
+	if !ok {
+		return fn(db.Session(&gorm.Session{Context: ctx}))
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, lock.try, lock.key).Scan(&acquired); err != nil {
+		return fmt.Errorf("acquire setup lock: %w", err)
+	}
+	if !acquired {
+		logger.Info("Waiting for another instance to finish database setup")
+		if err := conn.QueryRowContext(ctx, lock.wait, lock.key).Scan(&acquired); err != nil {
+			return fmt.Errorf("acquire setup lock: %w", err)
+		}
+		if !acquired {
+			return fmt.Errorf("acquire setup lock: timed out")
+		}
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), lock.release, lock.key)
+
+	locked := db.Session(&gorm.Session{Context: ctx})
+	locked.Statement.ConnPool = conn
+	return fn(locked)
+}
+
+// instanceID names this process in logs about shared database work.
+func instanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s/%d", host, os.Getpid())
+}
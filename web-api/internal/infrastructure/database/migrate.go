@@ -0,0 +1,409 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The migrations of each dialect are in migrations/<dialect>, with the same
+// versions and names, so that every database goes through the same schema
+// history.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// ErrSchemaNotCurrent is returned by Verify when migrations are pending.
+var ErrSchemaNotCurrent = errors.New("database schema is not current")
+
+// Migration is a versioned schema change read from NNNN_name.up.sql and the
+// optional NNNN_name.down.sql. Each migration runs in a transaction together
+// with its record in schema_migrations, so a failed migration leaves no
+// trace. MySQL is the exception: it commits every CREATE, ALTER and DROP
+// at once, so its migrations hold one schema change each where possible.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+	// Checksum is the SHA-256 of Up. Applied migrations must not change.
+	Checksum string
+}
+
+// MigrationStatus describes a migration known to the code, the database or
+// both.
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+	// Modified reports that the file differs from the one that was applied.
+	Modified bool
+	// Unknown reports an applied migration that this build does not have,
+	// i.e. the database was migrated by a newer version.
+	Unknown bool
+}
+
+type schemaMigration struct {
+	Version   int64  `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"not null"`
+	Checksum  string `gorm:"not null"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// createSchemaMigrations holds the DDL of schema_migrations by dialect.
+var createSchemaMigrations = map[string]string{
+	postgresDialect: `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    bigint PRIMARY KEY,
+    name       text NOT NULL,
+    checksum   text NOT NULL,
+    applied_at timestamptz NOT NULL
+)`,
+	mysqlDialect: `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    bigint PRIMARY KEY,
+    name       varchar(255) NOT NULL,
+    checksum   char(64) NOT NULL,
+    applied_at datetime(6) NOT NULL
+)`,
+	sqliteDialect: `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    integer PRIMARY KEY,
+    name       text NOT NULL,
+    checksum   text NOT NULL,
+    applied_at datetime NOT NULL
+)`,
+}
+
+// Migrator applies the embedded migrations of the database's dialect and
+// tracks them in the schema_migrations table.
+type Migrator struct {
+	db         *gorm.DB
+	logger     *slog.Logger
+	migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB, logger *slog.Logger) (*Migrator, error) {
+	dialect := db.Dialector.Name()
+	if _, ok := createSchemaMigrations[dialect]; !ok {
+		return nil, fmt.Errorf("no migrations for %s databases", dialect)
+	}
+	fsys, err := fs.Sub(migrationFiles, "migrations/"+dialect)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         db,
+		logger:     logger.With("instance", instanceID()),
+		migrations: migrations,
+	}, nil
+}
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s is not named NNNN_name.up.sql or NNNN_name.down.sql", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration file %s: invalid version", entry.Name())
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has files with different names", version)
+		}
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		sum := sha256.Sum256([]byte(m.Up))
+		m.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, *m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return migrations, nil
+}
+
+// Status lists every migration ordered by version.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	return m.status(ctx, m.db)
+}
+
+func (m *Migrator) status(ctx context.Context, db *gorm.DB) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &record.AppliedAt
+			status.Modified = record.Checksum != migration.Checksum
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range applied {
+		statuses = append(statuses, MigrationStatus{
+			Version:   record.Version,
+			Name:      record.Name,
+			AppliedAt: &record.AppliedAt,
+			Unknown:   true,
+		})
+	}
+	slices.SortFunc(statuses, func(a, b MigrationStatus) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return statuses, nil
+}
+
+// Verify checks that every migration is applied unmodified. It returns
+// ErrSchemaNotCurrent when migrations are pending.
+func (m *Migrator) Verify(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if err := checkConsistent(statuses); err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			pending = append(pending, fmt.Sprintf("%d_%s", s.Version, s.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: pending migrations %s", ErrSchemaNotCurrent, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.Goto(ctx, m.latest())
+}
+
+// Down rolls back the last steps applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	var applied []int64
+	for _, s := range statuses {
+		if s.AppliedAt != nil {
+			applied = append(applied, s.Version)
+		}
+	}
+	if steps > len(applied) {
+		steps = len(applied)
+	}
+	if steps <= 0 {
+		return nil
+	}
+
+	target := int64(0)
+	if i := len(applied) - steps - 1; i >= 0 {
+		target = applied[i]
+	}
+	return m.Goto(ctx, target)
+}
+
+// Goto migrates up or down so that exactly the migrations up to and
+// including version are applied. Version 0 rolls back every migration.
+// Instances migrating at the same time take turns, so the later ones find
+// nothing left to do.
+func (m *Migrator) Goto(ctx context.Context, version int64) error {
+	if version != 0 && !slices.ContainsFunc(m.migrations, func(migration Migration) bool {
+		return migration.Version == version
+	}) {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	return withSetupLock(ctx, m.db, m.logger, func(db *gorm.DB) error {
+		return m.migrate(ctx, db, version)
+	})
+}
+
+func (m *Migrator) migrate(ctx context.Context, db *gorm.DB, version int64) error {
+	if err := db.Exec(createSchemaMigrations[db.Dialector.Name()]).Error; err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	statuses, err := m.status(ctx, db)
+	if err != nil {
+		return err
+	}
+	if err := checkConsistent(statuses); err != nil {
+		return err
+	}
+	appliedVersions := make(map[int64]bool)
+	for _, s := range statuses {
+		if s.AppliedAt != nil {
+			appliedVersions[s.Version] = true
+		}
+	}
+
+	changed := 0
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if migration.Version > version && appliedVersions[migration.Version] {
+			if err := m.down(db, migration); err != nil {
+				return err
+			}
+			changed++
+		}
+	}
+	for _, migration := range m.migrations {
+		if migration.Version <= version && !appliedVersions[migration.Version] {
+			if err := m.up(db, migration); err != nil {
+				return err
+			}
+			changed++
+		}
+	}
+
+	if changed == 0 {
+		m.logger.Info("Database schema is current, no migrations to run", "version", version)
+	} else {
+		m.logger.Info("Database migrated by this instance", "version", version, "migrations", changed)
+	}
+	return nil
+}
+
+func (m *Migrator) up(db *gorm.DB, migration Migration) error {
+	m.logger.Info("Applying migration", "version", migration.Version, "name", migration.Name)
+	start := time.Now()
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(migration.Up).Error; err != nil {
+			return err
+		}
+		return tx.Create(&schemaMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Checksum:  migration.Checksum,
+			AppliedAt: time.Now().UTC(),
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	m.logger.Info("Applied migration", "version", migration.Version, "name", migration.Name, "duration", time.Since(start))
+	return nil
+}
+
+func (m *Migrator) down(db *gorm.DB, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("migration %d_%s cannot be rolled back, it has no down file", migration.Version, migration.Name)
+	}
+	m.logger.Info("Rolling back migration", "version", migration.Version, "name", migration.Name)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(migration.Down).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&schemaMigration{}, migration.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("rollback of migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// applied returns the records of schema_migrations. A missing table means
+// that nothing has been applied yet; it is only created when migrating, so
+// that Status and Verify never change the database.
+func (m *Migrator) applied(ctx context.Context, db *gorm.DB) (map[int64]schemaMigration, error) {
+	db = db.WithContext(ctx)
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return map[int64]schemaMigration{}, nil
+	}
+
+	var records []schemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]schemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+func (m *Migrator) latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// checkConsistent refuses to migrate a database whose history does not
+// match the code: an edited migration would never run again, and an
+// unknown one means this build is older than the schema.
+func checkConsistent(statuses []MigrationStatus) error {
+	var problems []string
+	for _, s := range statuses {
+		switch {
+		case s.Modified:
+			problems = append(problems, fmt.Sprintf("migration %d_%s was modified after it was applied", s.Version, s.Name))
+		case s.Unknown:
+			problems = append(problems, fmt.Sprintf("migration %d_%s is applied but unknown to this build", s.Version, s.Name))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+-- Tables use the utf8mb4_bin collation, so that usernames and emails are
+-- compared case-sensitively as in PostgreSQL. Indexed strings are
+-- varchar(255): a unique index of 255 utf8mb4 characters needs the DYNAMIC
+-- row format, the default since MySQL 5.7 and MariaDB 10.2.
+CREATE TABLE IF NOT EXISTS users (
+    id            bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name          text NOT NULL,
+    username      varchar(255) NOT NULL,
+    email         varchar(255) NOT NULL,
+    role          varchar(32) NOT NULL DEFAULT 'user',
+    password_hash varchar(255) NOT NULL DEFAULT '',
+    created_at    datetime(6),
+    updated_at    datetime(6),
+    UNIQUE INDEX idx_users_username (username),
+    UNIQUE INDEX idx_users_email (email)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS posts (
+    id         bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    title      text NOT NULL,
+    body       mediumtext,
+    created_at datetime(6),
+    updated_at datetime(6),
+    INDEX idx_posts_user_id (user_id),
+    CONSTRAINT fk_users_posts FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
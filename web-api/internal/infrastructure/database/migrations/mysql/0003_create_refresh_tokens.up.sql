@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id         varchar(32) NOT NULL PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    family_id  varchar(32) NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    expires_at datetime(6) NOT NULL,
+    revoked_at datetime(6),
+    created_at datetime(6),
+    INDEX idx_refresh_tokens_user_id (user_id),
+    INDEX idx_refresh_tokens_family_id (family_id),
+    UNIQUE INDEX idx_refresh_tokens_token_hash (token_hash),
+    CONSTRAINT fk_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           varchar(16) NOT NULL PRIMARY KEY,
+    user_id      bigint NOT NULL,
+    name         text NOT NULL,
+    key_hash     varchar(64) NOT NULL,
+    scopes       text NOT NULL,
+    expires_at   datetime(6),
+    last_used_at datetime(6),
+    created_at   datetime(6),
+    INDEX idx_api_keys_user_id (user_id),
+    CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
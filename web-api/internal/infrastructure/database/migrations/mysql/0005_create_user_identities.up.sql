@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS user_identities (
+    id         bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    issuer     varchar(255) NOT NULL,
+    subject    varchar(255) NOT NULL,
+    created_at datetime(6),
+    INDEX idx_user_identities_user_id (user_id),
+    UNIQUE INDEX idx_identities_issuer_subject (issuer, subject),
+    CONSTRAINT fk_user_identities_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
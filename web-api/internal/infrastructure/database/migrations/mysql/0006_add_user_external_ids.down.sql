@@ -0,0 +1,3 @@
+ALTER TABLE users
+    DROP INDEX idx_users_external_id,
+    DROP COLUMN external_id;
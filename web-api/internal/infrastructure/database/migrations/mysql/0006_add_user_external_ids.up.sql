@@ -0,0 +1,6 @@
+-- external_id is the ID of a record imported from JSONPlaceholder. It is
+-- NULL for records created locally. The column and its index are added by
+-- one statement, since MySQL commits each one at once.
+ALTER TABLE users
+    ADD COLUMN external_id bigint,
+    ADD UNIQUE INDEX idx_users_external_id (external_id);
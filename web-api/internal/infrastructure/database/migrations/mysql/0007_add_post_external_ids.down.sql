@@ -0,0 +1,3 @@
+ALTER TABLE posts
+    DROP INDEX idx_posts_external_id,
+    DROP COLUMN external_id;
@@ -0,0 +1,5 @@
+-- external_id is the ID of a post imported from JSONPlaceholder. It is NULL
+-- for posts created locally.
+ALTER TABLE posts
+    ADD COLUMN external_id bigint,
+    ADD UNIQUE INDEX idx_posts_external_id (external_id);
@@ -0,0 +1,15 @@
+-- Tables use IF NOT EXISTS so that databases created by the former
+-- AutoMigrate adopt the versioned migrations without changes.
+CREATE TABLE IF NOT EXISTS users (
+    id            bigserial PRIMARY KEY,
+    name          text NOT NULL,
+    username      text NOT NULL,
+    email         text NOT NULL,
+    role          text NOT NULL DEFAULT 'user',
+    password_hash text NOT NULL DEFAULT '',
+    created_at    timestamptz,
+    updated_at    timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS posts (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    title      text NOT NULL,
+    body       text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT fk_users_posts FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_posts_user_id ON posts (user_id);
@@ -0,0 +1,4 @@
+-- external_id is the ID of a record imported from JSONPlaceholder. It is
+-- NULL for records created locally.
+ALTER TABLE users ADD COLUMN external_id bigint;
+CREATE UNIQUE INDEX idx_users_external_id ON users (external_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_posts_external_id;
+ALTER TABLE posts DROP COLUMN IF EXISTS external_id;
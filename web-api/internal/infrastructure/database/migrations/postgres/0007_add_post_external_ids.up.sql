@@ -0,0 +1,4 @@
+-- external_id is the ID of a post imported from JSONPlaceholder. It is NULL
+-- for posts created locally.
+ALTER TABLE posts ADD COLUMN external_id bigint;
+CREATE UNIQUE INDEX idx_posts_external_id ON posts (external_id);
@@ -0,0 +1,13 @@
+CREATE TABLE users (
+    id            integer PRIMARY KEY AUTOINCREMENT,
+    name          text NOT NULL,
+    username      text NOT NULL,
+    email         text NOT NULL,
+    role          text NOT NULL DEFAULT 'user',
+    password_hash text NOT NULL DEFAULT '',
+    created_at    datetime,
+    updated_at    datetime
+);
+
+CREATE UNIQUE INDEX idx_users_username ON users (username);
+CREATE UNIQUE INDEX idx_users_email ON users (email);
@@ -0,0 +1,11 @@
+CREATE TABLE posts (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    user_id    integer NOT NULL,
+    title      text NOT NULL,
+    body       text,
+    created_at datetime,
+    updated_at datetime,
+    CONSTRAINT fk_users_posts FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX idx_posts_user_id ON posts (user_id);
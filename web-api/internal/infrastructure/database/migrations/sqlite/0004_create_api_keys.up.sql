@@ -0,0 +1,13 @@
+CREATE TABLE api_keys (
+    id           varchar(16) PRIMARY KEY,
+    user_id      integer NOT NULL,
+    name         text NOT NULL,
+    key_hash     varchar(64) NOT NULL,
+    scopes       text NOT NULL,
+    expires_at   datetime,
+    last_used_at datetime,
+    created_at   datetime,
+    CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_api_keys_user_id ON api_keys (user_id);
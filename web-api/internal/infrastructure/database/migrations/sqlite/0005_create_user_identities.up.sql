@@ -0,0 +1,11 @@
+CREATE TABLE user_identities (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    user_id    integer NOT NULL,
+    issuer     text NOT NULL,
+    subject    text NOT NULL,
+    created_at datetime,
+    CONSTRAINT fk_user_identities_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_identities_user_id ON user_identities (user_id);
+CREATE UNIQUE INDEX idx_identities_issuer_subject ON user_identities (issuer, subject);
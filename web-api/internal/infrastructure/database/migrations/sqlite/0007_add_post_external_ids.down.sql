@@ -0,0 +1,3 @@
+-- SQLite cannot drop a column that is indexed, so the index goes first.
+DROP INDEX IF EXISTS idx_posts_external_id;
+ALTER TABLE posts DROP COLUMN external_id;
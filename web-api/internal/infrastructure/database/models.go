@@ -2,28 +2,62 @@ package database
 
 import (
 	"time"
-
-	"gorm.io/gorm"
 )
 
 type User struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Name      string    `gorm:"not null" json:"name"`
-	Username  string    `gorm:"uniqueIndex;not null" json:"username"`
-	Email     string    `gorm:"uniqueIndex;not null" json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Posts     []Post    `gorm:"foreignKey:UserID" json:"posts,omitempty"`
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Name         string    `gorm:"not null" json:"name"`
+	Username     string    `gorm:"uniqueIndex;not null;size:255" json:"username"`
+	Email        string    `gorm:"uniqueIndex;not null;size:255" json:"email"`
+	Role         string    `gorm:"not null;default:'user'" json:"role"`
+	PasswordHash string    `gorm:"not null;default:''" json:"-"`
+	ExternalID   *int64    `gorm:"uniqueIndex" json:"external_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Posts        []Post    `gorm:"foreignKey:UserID" json:"posts,omitempty"`
 }
 
 type Post struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"not null;index" json:"user_id"`
+	Title      string    `gorm:"not null" json:"title"`
+	Body       string    `gorm:"type:text" json:"body"`
+	ExternalID *int64    `gorm:"uniqueIndex" json:"external_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	User       User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+type RefreshToken struct {
+	ID        string     `gorm:"primaryKey;size:32" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	FamilyID  string     `gorm:"not null;index;size:32" json:"family_id"`
+	TokenHash string     `gorm:"uniqueIndex;not null;size:64" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	User      User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+type APIKey struct {
+	ID         string     `gorm:"primaryKey;size:16" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Name       string     `gorm:"not null" json:"name"`
+	KeyHash    string     `gorm:"not null;size:64" json:"-"`
+	Scopes     string     `gorm:"not null" json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	User       User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+type Identity struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	UserID    uint      `gorm:"not null;index" json:"user_id"`
-	Title     string    `gorm:"not null" json:"title"`
-	Body      string    `gorm:"type:text" json:"body"`
+	Issuer    string    `gorm:"not null;size:255;uniqueIndex:idx_identities_issuer_subject" json:"issuer"`
+	Subject   string    `gorm:"not null;size:255;uniqueIndex:idx_identities_issuer_subject" json:"subject"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	User      User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
 }
 
 func (User) TableName() string {
@@ -34,6 +68,14 @@ func (Post) TableName() string {
 	return "posts"
 }
 
-func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(&User{}, &Post{})
-}
\ No newline at end of file
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+func (Identity) TableName() string {
+	return "user_identities"
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) FindByID(ctx context.Context, id string) (*entity.APIKey, error) {
+	var dbKey database.APIKey
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&dbKey).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return r.toEntity(dbKey)
+}
+
+func (r *APIKeyRepository) FindByUserID(ctx context.Context, userID valueobject.UserID) ([]*entity.APIKey, error) {
+	var dbKeys []database.APIKey
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID.Value()).Order("created_at").Find(&dbKeys).Error; err != nil {
+		return nil, err
+	}
+
+	keys := make([]*entity.APIKey, len(dbKeys))
+	for i, dbKey := range dbKeys {
+		key, err := r.toEntity(dbKey)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+
+	return keys, nil
+}
+
+func (r *APIKeyRepository) Save(ctx context.Context, key *entity.APIKey) error {
+	dbKey := r.fromEntity(key)
+	return r.db.WithContext(ctx).Create(dbKey).Error
+}
+
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&database.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
+
+func (r *APIKeyRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&database.APIKey{}, "id = ?", id).Error
+}
+
+func (r *APIKeyRepository) toEntity(dbKey database.APIKey) (*entity.APIKey, error) {
+	userID, err := valueobject.NewUserID(int(dbKey.UserID))
+	if err != nil {
+		return nil, err
+	}
+
+	var expiresAt, lastUsedAt time.Time
+	if dbKey.ExpiresAt != nil {
+		expiresAt = *dbKey.ExpiresAt
+	}
+	if dbKey.LastUsedAt != nil {
+		lastUsedAt = *dbKey.LastUsedAt
+	}
+
+	return entity.NewAPIKey(dbKey.ID, userID, dbKey.Name, dbKey.KeyHash, strings.Fields(dbKey.Scopes), expiresAt, lastUsedAt, dbKey.CreatedAt), nil
+}
+
+func (r *APIKeyRepository) fromEntity(key *entity.APIKey) *database.APIKey {
+	dbKey := &database.APIKey{
+		ID:        key.ID(),
+		UserID:    uint(key.UserID().Value()),
+		Name:      key.Name(),
+		KeyHash:   key.KeyHash(),
+		Scopes:    strings.Join(key.Scopes(), " "),
+		CreatedAt: key.CreatedAt(),
+	}
+	if !key.ExpiresAt().IsZero() {
+		expiresAt := key.ExpiresAt()
+		dbKey.ExpiresAt = &expiresAt
+	}
+	if !key.LastUsedAt().IsZero() {
+		lastUsedAt := key.LastUsedAt()
+		dbKey.LastUsedAt = &lastUsedAt
+	}
+	return dbKey
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database"
+	"gorm.io/gorm"
+)
+
+type IdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+func (r *IdentityRepository) FindBySubject(ctx context.Context, issuer, subject string) (*entity.Identity, error) {
+	var dbIdentity database.Identity
+	if err := r.db.WithContext(ctx).Where("issuer = ? AND subject = ?", issuer, subject).First(&dbIdentity).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	userID, err := valueobject.NewUserID(int(dbIdentity.UserID))
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.NewIdentity(dbIdentity.Issuer, dbIdentity.Subject, userID), nil
+}
+
+func (r *IdentityRepository) Save(ctx context.Context, identity *entity.Identity) error {
+	return r.db.WithContext(ctx).Create(&database.Identity{
+		UserID:  uint(identity.UserID().Value()),
+		Issuer:  identity.Issuer(),
+		Subject: identity.Subject(),
+	}).Error
+}
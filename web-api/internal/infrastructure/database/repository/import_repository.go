@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// importLockKey identifies the transaction-level advisory lock that makes
+// imports from several instances run one after another. MySQL has no
+// transaction-level locks, so there a named lock is held on the connection
+// until the transaction has ended. SQLite needs no lock, as its
+// transactions never run in parallel.
+const (
+	importLockKey  int64 = 0x7765626170690002
+	importLockName       = "web-api:import"
+)
+
+// errDryRun rolls back the transaction of a dry run.
+var errDryRun = errors.New("dry run")
+
+type ImportRepository struct {
+	db    *gorm.DB
+	users *UserRepository
+	posts *PostRepository
+}
+
+func NewImportRepository(db *gorm.DB) *ImportRepository {
+	return &ImportRepository{
+		db:    db,
+		users: NewUserRepository(db),
+		posts: NewPostRepository(db),
+	}
+}
+
+func (r *ImportRepository) Import(ctx context.Context, users []*entity.User, posts []*entity.Post, dryRun bool) (*repository.ImportResult, error) {
+	result := &repository.ImportResult{}
+
+	err := withImportLock(r.db.WithContext(ctx), func(db *gorm.DB) error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			if tx.Dialector.Name() == "postgres" {
+				if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", importLockKey).Error; err != nil {
+					return err
+				}
+			}
+
+			userIDs := make(map[int64]uint, len(users))
+			for _, user := range users {
+				id, err := r.importUser(tx, user, &result.Users)
+				if err != nil {
+					return err
+				}
+				userIDs[int64(user.ID().Value())] = id
+			}
+
+			for _, post := range posts {
+				if err := r.importPost(tx, post, userIDs, &result.Posts); err != nil {
+					return err
+				}
+			}
+
+			if dryRun {
+				return errDryRun
+			}
+			return nil
+		})
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *ImportRepository) FindUserByExternalID(ctx context.Context, id valueobject.UserID) (*entity.User, error) {
+	var dbUser database.User
+	if err := r.db.WithContext(ctx).Where("external_id = ?", id.Value()).First(&dbUser).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.users.toEntity(dbUser)
+}
+
+func (r *ImportRepository) FindPostByExternalID(ctx context.Context, id valueobject.PostID) (*entity.Post, error) {
+	var dbPost database.Post
+	if err := r.db.WithContext(ctx).Where("external_id = ?", id.Value()).First(&dbPost).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.posts.toEntity(dbPost)
+}
+
+// withImportLock runs fn on a connection holding the MySQL import lock. On
+// other databases it runs fn directly.
+func withImportLock(db *gorm.DB, fn func(db *gorm.DB) error) error {
+	if db.Dialector.Name() != "mysql" {
+		return fn(db)
+	}
+
+	return db.Connection(func(conn *gorm.DB) error {
+		var acquired bool
+		if err := conn.Raw("SELECT GET_LOCK(?, ?) = 1", importLockName, 24*60*60).Scan(&acquired).Error; err != nil {
+			return err
+		}
+		if !acquired {
+			return fmt.Errorf("acquire import lock: timed out")
+		}
+		defer conn.WithContext(context.WithoutCancel(conn.Statement.Context)).Exec("SELECT RELEASE_LOCK(?)", importLockName)
+		return fn(conn)
+	})
+}
+
+// importUser creates or updates the user and returns its local ID. The
+// role and password of users that already exist are left alone.
+func (r *ImportRepository) importUser(tx *gorm.DB, user *entity.User, counts *repository.ImportCounts) (uint, error) {
+	externalID := int64(user.ID().Value())
+	if user.Email().String() == "" {
+		return 0, fmt.Errorf("import user %d: invalid email", externalID)
+	}
+
+	var existing database.User
+	err := tx.Where("external_id = ?", externalID).Take(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		created := database.User{
+			Name:       user.Name(),
+			Username:   user.Username(),
+			Email:      user.Email().String(),
+			Role:       user.Role().String(),
+			ExternalID: &externalID,
+		}
+		if err := tx.Create(&created).Error; err != nil {
+			return 0, fmt.Errorf("import user %d (%s): %w", externalID, user.Username(), err)
+		}
+		counts.Created++
+		return created.ID, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if existing.Name == user.Name() && existing.Username == user.Username() && existing.Email == user.Email().String() {
+		counts.Unchanged++
+		return existing.ID, nil
+	}
+	err = tx.Model(&existing).Updates(map[string]any{
+		"name":     user.Name(),
+		"username": user.Username(),
+		"email":    user.Email().String(),
+	}).Error
+	if err != nil {
+		return 0, fmt.Errorf("import user %d (%s): %w", externalID, user.Username(), err)
+	}
+	counts.Updated++
+	return existing.ID, nil
+}
+
+// importPost creates or updates the post. Its author is resolved through
+// userIDs, or else among the users imported before.
+func (r *ImportRepository) importPost(tx *gorm.DB, post *entity.Post, userIDs map[int64]uint, counts *repository.ImportCounts) error {
+	externalID := int64(post.ID().Value())
+	externalUserID := int64(post.UserID().Value())
+
+	userID, ok := userIDs[externalUserID]
+	if !ok {
+		var author database.User
+		if err := tx.Select("id").Where("external_id = ?", externalUserID).Take(&author).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("import post %d: unknown user %d", externalID, externalUserID)
+			}
+			return err
+		}
+		userID = author.ID
+		userIDs[externalUserID] = userID
+	}
+
+	var existing database.Post
+	err := tx.Where("external_id = ?", externalID).Take(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		created := database.Post{
+			UserID:     userID,
+			Title:      post.Title(),
+			Body:       post.Body(),
+			ExternalID: &externalID,
+		}
+		if err := tx.Omit(clause.Associations).Create(&created).Error; err != nil {
+			return fmt.Errorf("import post %d: %w", externalID, err)
+		}
+		counts.Created++
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if existing.UserID == userID && existing.Title == post.Title() && existing.Body == post.Body() {
+		counts.Unchanged++
+		return nil
+	}
+	err = tx.Model(&existing).Updates(map[string]any{
+		"user_id": userID,
+		"title":   post.Title(),
+		"body":    post.Body(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("import post %d: %w", externalID, err)
+	}
+	counts.Updated++
+	return nil
+}
@@ -19,7 +19,7 @@ func NewPostRepository(db *gorm.DB) *PostRepository {
 
 func (r *PostRepository) FindAll(ctx context.Context) ([]*entity.Post, error) {
 	var dbPosts []database.Post
-	if err := r.db.WithContext(ctx).Find(&dbPosts).Error; err != nil {
+	if err := r.db.WithContext(ctx).Order("id").Find(&dbPosts).Error; err != nil {
 		return nil, err
 	}
 
@@ -49,7 +49,7 @@ func (r *PostRepository) FindByID(ctx context.Context, id valueobject.PostID) (*
 
 func (r *PostRepository) FindByUserID(ctx context.Context, userID valueobject.UserID) ([]*entity.Post, error) {
 	var dbPosts []database.Post
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userID.Value()).Find(&dbPosts).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID.Value()).Order("id").Find(&dbPosts).Error; err != nil {
 		return nil, err
 	}
 
@@ -65,14 +65,18 @@ func (r *PostRepository) FindByUserID(ctx context.Context, userID valueobject.Us
 	return posts, nil
 }
 
-func (r *PostRepository) Save(ctx context.Context, post *entity.Post) error {
+func (r *PostRepository) Save(ctx context.Context, post *entity.Post) (*entity.Post, error) {
 	dbPost := r.fromEntity(post)
-	return r.db.WithContext(ctx).Create(dbPost).Error
+	if err := r.db.WithContext(ctx).Create(dbPost).Error; err != nil {
+		return nil, err
+	}
+
+	return r.toEntity(*dbPost)
 }
 
 func (r *PostRepository) Update(ctx context.Context, post *entity.Post) error {
 	dbPost := r.fromEntity(post)
-	return r.db.WithContext(ctx).Save(dbPost).Error
+	return r.db.WithContext(ctx).Model(dbPost).Select("user_id", "title", "body").Updates(dbPost).Error
 }
 
 func (r *PostRepository) Delete(ctx context.Context, id valueobject.PostID) error {
@@ -100,4 +104,4 @@ func (r *PostRepository) fromEntity(post *entity.Post) *database.Post {
 		Title:  post.Title(),
 		Body:   post.Body(),
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	var dbToken database.RefreshToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&dbToken).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return r.toEntity(dbToken)
+}
+
+func (r *RefreshTokenRepository) Save(ctx context.Context, token *entity.RefreshToken) error {
+	dbToken := r.fromEntity(token)
+	return r.db.WithContext(ctx).Create(dbToken).Error
+}
+
+func (r *RefreshTokenRepository) Revoke(ctx context.Context, id string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&database.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.db.WithContext(ctx).Model(&database.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID valueobject.UserID) error {
+	return r.db.WithContext(ctx).Model(&database.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID.Value()).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *RefreshTokenRepository) toEntity(dbToken database.RefreshToken) (*entity.RefreshToken, error) {
+	userID, err := valueobject.NewUserID(int(dbToken.UserID))
+	if err != nil {
+		return nil, err
+	}
+
+	var revokedAt time.Time
+	if dbToken.RevokedAt != nil {
+		revokedAt = *dbToken.RevokedAt
+	}
+
+	return entity.NewRefreshToken(dbToken.ID, userID, dbToken.FamilyID, dbToken.TokenHash, dbToken.ExpiresAt, revokedAt), nil
+}
+
+func (r *RefreshTokenRepository) fromEntity(token *entity.RefreshToken) *database.RefreshToken {
+	dbToken := &database.RefreshToken{
+		ID:        token.ID(),
+		UserID:    uint(token.UserID().Value()),
+		FamilyID:  token.FamilyID(),
+		TokenHash: token.TokenHash(),
+		ExpiresAt: token.ExpiresAt(),
+	}
+	if token.IsRevoked() {
+		revokedAt := token.RevokedAt()
+		dbToken.RevokedAt = &revokedAt
+	}
+	return dbToken
+}
@@ -0,0 +1,19 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository/repositorytest"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database/databasetest"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database/repository"
+)
+
+func TestRepositories(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Subject {
+		db := databasetest.SQLite(t)
+		return repositorytest.Subject{
+			Users: repository.NewUserRepository(db),
+			Posts: repository.NewPostRepository(db),
+		}
+	})
+}
@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database"
 	"gorm.io/gorm"
@@ -19,7 +22,7 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 
 func (r *UserRepository) FindAll(ctx context.Context) ([]*entity.User, error) {
 	var dbUsers []database.User
-	if err := r.db.WithContext(ctx).Find(&dbUsers).Error; err != nil {
+	if err := r.db.WithContext(ctx).Order("id").Find(&dbUsers).Error; err != nil {
 		return nil, err
 	}
 
@@ -59,14 +62,30 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email valueobject.Emai
 	return r.toEntity(dbUser)
 }
 
-func (r *UserRepository) Save(ctx context.Context, user *entity.User) error {
+func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
+	var dbUser database.User
+	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&dbUser).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return r.toEntity(dbUser)
+}
+
+func (r *UserRepository) Save(ctx context.Context, user *entity.User) (*entity.User, error) {
 	dbUser := r.fromEntity(user)
-	return r.db.WithContext(ctx).Create(dbUser).Error
+	if err := r.db.WithContext(ctx).Create(dbUser).Error; err != nil {
+		return nil, translate(r.db, err)
+	}
+
+	return r.toEntity(*dbUser)
 }
 
 func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
 	dbUser := r.fromEntity(user)
-	return r.db.WithContext(ctx).Save(dbUser).Error
+	return translate(r.db, r.db.WithContext(ctx).Model(dbUser).Select("name", "username", "email", "role", "password_hash").Updates(dbUser).Error)
 }
 
 func (r *UserRepository) Delete(ctx context.Context, id valueobject.UserID) error {
@@ -84,14 +103,34 @@ func (r *UserRepository) toEntity(dbUser database.User) (*entity.User, error) {
 		return nil, err
 	}
 
-	return entity.NewUser(userID, dbUser.Name, dbUser.Username, email), nil
+	role, err := valueobject.NewRole(dbUser.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	user := entity.NewUser(userID, dbUser.Name, dbUser.Username, email)
+	user.SetRole(role)
+	user.SetPasswordHash(dbUser.PasswordHash)
+	return user, nil
 }
 
 func (r *UserRepository) fromEntity(user *entity.User) *database.User {
 	return &database.User{
-		ID:       uint(user.ID().Value()),
-		Name:     user.Name(),
-		Username: user.Username(),
-		Email:    user.Email().String(),
+		ID:           uint(user.ID().Value()),
+		Name:         user.Name(),
+		Username:     user.Username(),
+		Email:        user.Email().String(),
+		Role:         user.Role().String(),
+		PasswordHash: user.PasswordHash(),
 	}
-}
\ No newline at end of file
+}
+
+// translate maps violations of unique indexes to repository.ErrDuplicate,
+// keeping the message of the database.
+func translate(db *gorm.DB, err error) error {
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok && err != nil &&
+		errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey) {
+		return fmt.Errorf("%w: %v", repository.ErrDuplicate, err)
+	}
+	return err
+}
@@ -0,0 +1,247 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Seed profiles select the data inserted on startup.
+const (
+	// SeedNone inserts nothing.
+	SeedNone = "none"
+	// SeedDemo inserts a few users and posts for local development.
+	SeedDemo = "demo"
+	// SeedLoadTest generates many users and posts for performance testing.
+	SeedLoadTest = "load-test"
+)
+
+// seedBatchSize bounds the rows per INSERT and the IDs per lookup.
+const seedBatchSize = 500
+
+// SeedOptions select the seed data.
+type SeedOptions struct {
+	Profile string
+	// Fixtures is a JSON or YAML fixture file, or a directory of them,
+	// seeded in addition to the profile.
+	Fixtures string
+	// Users and PostsPerUser size the load-test profile.
+	Users        int
+	PostsPerUser int
+}
+
+// SeedData inserts the seed data. It can run any number of times and from
+// several instances at once: users that already exist, by username, get
+// the name and email of their fixture but keep their role, and posts are
+// only added when their author has no post with the same title. A user
+// whose email belongs to another username is skipped with its posts and
+// reported in the log.
+func SeedData(ctx context.Context, db *gorm.DB, logger *slog.Logger, opts SeedOptions) error {
+	data, err := seedFixtures(opts)
+	if err != nil || len(data.Users) == 0 && len(data.Posts) == 0 {
+		return err
+	}
+
+	logger = logger.With("instance", instanceID(), "profile", opts.Profile)
+	return withSetupLock(ctx, db, logger, func(db *gorm.DB) error {
+		var users, posts int64
+		var skipped map[string]bool
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			if users, skipped, err = seedUsers(tx, logger, data.Users); err != nil {
+				return err
+			}
+			posts, err = seedPosts(tx, data.Posts, skipped)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Seed data applied by this instance", "users", users, "posts", posts, "skipped_users", len(skipped))
+		return nil
+	})
+}
+
+// seedFixtures reads and validates the fixtures opts select.
+func seedFixtures(opts SeedOptions) (fixtures, error) {
+	var data fixtures
+	switch opts.Profile {
+	case SeedNone:
+	case SeedDemo:
+		demo, err := embeddedFixtures("demo")
+		if err != nil {
+			return fixtures{}, err
+		}
+		data.add(demo)
+	case SeedLoadTest:
+		data.add(generateFixtures(opts.Users, opts.PostsPerUser))
+	default:
+		return fixtures{}, fmt.Errorf("unknown seed profile %q", opts.Profile)
+	}
+	if opts.Fixtures != "" {
+		extra, err := loadFixtures(opts.Fixtures)
+		if err != nil {
+			return fixtures{}, err
+		}
+		data.add(extra)
+	}
+
+	if err := data.validate(); err != nil {
+		return fixtures{}, err
+	}
+	return data, nil
+}
+
+// seedUsers upserts the users by username and returns the usernames it
+// skipped because their email belongs to another user.
+func seedUsers(tx *gorm.DB, logger *slog.Logger, fixtures []userFixture) (int64, map[string]bool, error) {
+	if len(fixtures) == 0 {
+		return 0, nil, nil
+	}
+
+	owners, err := emailOwners(tx, fixtures)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	skipped := make(map[string]bool)
+	users := make([]User, 0, len(fixtures))
+	for _, f := range fixtures {
+		if owner, ok := owners[f.Email]; ok && owner != f.Username {
+			logger.Warn("Seed user skipped, its email belongs to another user", "username", f.Username, "owner", owner)
+			skipped[f.Username] = true
+			continue
+		}
+		role := f.Role
+		if role == "" {
+			role = "user"
+		}
+		users = append(users, User{Name: f.Name, Username: f.Username, Email: f.Email, Role: role})
+	}
+	if len(users) == 0 {
+		return 0, skipped, nil
+	}
+
+	// The role is only seeded with the user, so that roles changed since
+	// survive restarts.
+	result := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "username"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "email", "updated_at"}),
+	}).CreateInBatches(users, seedBatchSize)
+	return result.RowsAffected, skipped, result.Error
+}
+
+// emailOwners returns the usernames of the existing users with the emails
+// of fixtures.
+func emailOwners(tx *gorm.DB, fixtures []userFixture) (map[string]string, error) {
+	emails := make([]string, len(fixtures))
+	for i, f := range fixtures {
+		emails[i] = f.Email
+	}
+
+	owners := make(map[string]string)
+	for batch := range slices.Chunk(emails, seedBatchSize) {
+		var users []User
+		if err := tx.Select("username", "email").Where("email IN ?", batch).Find(&users).Error; err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			owners[u.Email] = u.Username
+		}
+	}
+	return owners, nil
+}
+
+// seedPosts adds the posts their authors do not have yet. The posts of
+// skipped users are skipped as well.
+func seedPosts(tx *gorm.DB, fixtures []postFixture, skipped map[string]bool) (int64, error) {
+	if len(fixtures) == 0 {
+		return 0, nil
+	}
+
+	authors, err := userIDs(tx, fixtures)
+	if err != nil {
+		return 0, err
+	}
+	existing, err := postTitles(tx, authors)
+	if err != nil {
+		return 0, err
+	}
+
+	var posts []Post
+	for _, f := range fixtures {
+		if skipped[f.Author] {
+			continue
+		}
+		userID, ok := authors[f.Author]
+		if !ok {
+			return 0, fmt.Errorf("post %q refers to unknown user %q", f.Title, f.Author)
+		}
+		key := postKey{userID: userID, title: f.Title}
+		if existing[key] {
+			continue
+		}
+		existing[key] = true
+		posts = append(posts, Post{UserID: userID, Title: f.Title, Body: f.Body})
+	}
+	if len(posts) == 0 {
+		return 0, nil
+	}
+
+	result := tx.Omit(clause.Associations).CreateInBatches(posts, seedBatchSize)
+	return result.RowsAffected, result.Error
+}
+
+// userIDs resolves the authors of posts by username.
+func userIDs(tx *gorm.DB, fixtures []postFixture) (map[string]uint, error) {
+	var usernames []string
+	seen := make(map[string]bool)
+	for _, f := range fixtures {
+		if !seen[f.Author] {
+			seen[f.Author] = true
+			usernames = append(usernames, f.Author)
+		}
+	}
+
+	ids := make(map[string]uint, len(usernames))
+	for batch := range slices.Chunk(usernames, seedBatchSize) {
+		var users []User
+		if err := tx.Select("id", "username").Where("username IN ?", batch).Find(&users).Error; err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			ids[u.Username] = u.ID
+		}
+	}
+	return ids, nil
+}
+
+type postKey struct {
+	userID uint
+	title  string
+}
+
+// postTitles returns the posts the authors already have.
+func postTitles(tx *gorm.DB, authors map[string]uint) (map[postKey]bool, error) {
+	userIDs := make([]uint, 0, len(authors))
+	for _, id := range authors {
+		userIDs = append(userIDs, id)
+	}
+
+	existing := make(map[postKey]bool)
+	for batch := range slices.Chunk(userIDs, seedBatchSize) {
+		var posts []Post
+		if err := tx.Select("user_id", "title").Where("user_id IN ?", batch).Find(&posts).Error; err != nil {
+			return nil, err
+		}
+		for _, p := range posts {
+			existing[postKey{userID: p.UserID, title: p.Title}] = true
+		}
+	}
+	return existing, nil
+}
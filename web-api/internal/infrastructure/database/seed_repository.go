@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+// SeedRepositories inserts the seed data through the domain repositories,
+// for stores other than the database such as the in-memory ones. It skips
+// the same users and posts as SeedData.
+func SeedRepositories(ctx context.Context, users repository.UserRepository, posts repository.PostRepository, logger *slog.Logger, opts SeedOptions) error {
+	data, err := seedFixtures(opts)
+	if err != nil || len(data.Users) == 0 && len(data.Posts) == 0 {
+		return err
+	}
+
+	var createdUsers, createdPosts int
+	for _, f := range data.Users {
+		created, err := seedUser(ctx, users, f)
+		if err != nil {
+			return err
+		}
+		if created {
+			createdUsers++
+		}
+	}
+
+	authors := make(map[string]*entity.User)
+	titles := make(map[valueobject.UserID]map[string]bool)
+	for _, f := range data.Posts {
+		author, ok := authors[f.Author]
+		if !ok {
+			if author, err = users.FindByUsername(ctx, f.Author); err != nil {
+				return err
+			}
+			if author == nil {
+				return fmt.Errorf("post %q refers to unknown user %q", f.Title, f.Author)
+			}
+			authors[f.Author] = author
+		}
+
+		existing, ok := titles[author.ID()]
+		if !ok {
+			authorPosts, err := posts.FindByUserID(ctx, author.ID())
+			if err != nil {
+				return err
+			}
+			existing = make(map[string]bool, len(authorPosts))
+			for _, p := range authorPosts {
+				existing[p.Title()] = true
+			}
+			titles[author.ID()] = existing
+		}
+		if existing[f.Title] {
+			continue
+		}
+
+		if _, err := posts.Save(ctx, entity.NewPost(valueobject.PostID{}, author.ID(), f.Title, f.Body)); err != nil {
+			return fmt.Errorf("seed post %q: %w", f.Title, err)
+		}
+		existing[f.Title] = true
+		createdPosts++
+	}
+
+	logger.Info("Seed data inserted", "profile", opts.Profile, "users", createdUsers, "posts", createdPosts)
+	return nil
+}
+
+// seedUser saves the user unless one with its username or email exists.
+func seedUser(ctx context.Context, users repository.UserRepository, f userFixture) (bool, error) {
+	email, err := valueobject.NewEmail(f.Email)
+	if err != nil {
+		return false, err
+	}
+
+	existing, err := users.FindByUsername(ctx, f.Username)
+	if err == nil && existing == nil {
+		existing, err = users.FindByEmail(ctx, email)
+	}
+	if err != nil || existing != nil {
+		return false, err
+	}
+
+	user := entity.NewUser(valueobject.UserID{}, f.Name, f.Username, email)
+	if f.Role != "" {
+		role, err := valueobject.NewRole(f.Role)
+		if err != nil {
+			return false, err
+		}
+		user.SetRole(role)
+	}
+	if _, err := users.Save(ctx, user); err != nil {
+		return false, fmt.Errorf("seed user %s: %w", f.Username, err)
+	}
+	return true, nil
+}
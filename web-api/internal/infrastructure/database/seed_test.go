@@ -0,0 +1,74 @@
+package database_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database/databasetest"
+)
+
+func TestSeedDataUpsertsUsers(t *testing.T) {
+	db := databasetest.SQLite(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	fixtures := filepath.Join(t.TempDir(), "users.yaml")
+
+	seed := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(fixtures, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		opts := database.SeedOptions{Profile: database.SeedNone, Fixtures: fixtures}
+		if err := database.SeedData(context.Background(), db, logger, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seed(`
+users:
+  - {username: ada, name: Ada, email: ada@example.com}
+posts:
+  - {author: ada, title: Notes, body: First}
+`)
+	// An admin promotes the seeded user, and someone registers with the
+	// email of a fixture.
+	if err := db.Model(&database.User{}).Where("username = ?", "ada").Update("role", "admin").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&database.User{Name: "Robert", Username: "robert", Email: "bob@example.com", Role: "user"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	// bob's email belongs to robert, so bob and his posts are skipped
+	// without failing the seed.
+	seed(`
+users:
+  - {username: ada, name: Ada Lovelace, email: ada@example.org}
+  - {username: bob, name: Bob, email: bob@example.com}
+posts:
+  - {author: ada, title: Notes, body: Second}
+  - {author: bob, title: Hello, body: Bob}
+`)
+
+	var users []database.User
+	if err := db.Order("id").Find(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[1].Username != "robert" || users[1].Name != "Robert" {
+		t.Fatalf("users = %+v, want ada and robert untouched", users)
+	}
+	if u := users[0]; u.Name != "Ada Lovelace" || u.Email != "ada@example.org" || u.Role != "admin" {
+		t.Errorf("user = %q %q %q, want the second fixture with the role kept", u.Name, u.Email, u.Role)
+	}
+
+	var posts []database.Post
+	if err := db.Find(&posts).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 || posts[0].UserID != users[0].ID || posts[0].Body != "First" {
+		t.Errorf("posts = %+v, want the first post only", posts)
+	}
+}
@@ -0,0 +1,109 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository/repositorytest"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database/databasetest"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database/repository"
+)
+
+func TestSQLiteRepositories(t *testing.T) {
+	newSubject := func(t *testing.T) repositorytest.Subject {
+		db := databasetest.Open(t, sqliteFile(t))
+		return repositorytest.Subject{
+			Users: repository.NewUserRepository(db),
+			Posts: repository.NewPostRepository(db),
+		}
+	}
+	t.Run("Users", func(t *testing.T) { repositorytest.RunUserRepository(t, newSubject) })
+	t.Run("Posts", func(t *testing.T) { repositorytest.RunPostRepository(t, newSubject) })
+}
+
+func TestSQLiteMigrateRoundTrip(t *testing.T) {
+	db, err := database.Connect(database.Options{URL: sqliteFile(t), LogLevel: "silent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	testMigrateRoundTrip(t, db)
+}
+
+func sqliteFile(t *testing.T) string {
+	return "sqlite://" + filepath.Join(t.TempDir(), "test.db")
+}
+
+// testMigrateRoundTrip migrates db up, all the way down and up again, and
+// checks the schema after each step.
+func testMigrateRoundTrip(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	ctx := context.Background()
+
+	migrator, err := database.NewMigrator(db, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables := []string{"users", "posts", "refresh_tokens", "api_keys", "user_identities"}
+
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	checkApplied(t, db, migrator, tables)
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Down(ctx, len(statuses)); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	for _, table := range tables {
+		if db.Migrator().HasTable(table) {
+			t.Errorf("table %s exists after Down", table)
+		}
+	}
+	if err := migrator.Verify(ctx); !errors.Is(err, database.ErrSchemaNotCurrent) {
+		t.Errorf("Verify after Down = %v, want ErrSchemaNotCurrent", err)
+	}
+
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up after Down: %v", err)
+	}
+	checkApplied(t, db, migrator, tables)
+}
+
+func checkApplied(t *testing.T, db *gorm.DB, migrator *database.Migrator, tables []string) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := migrator.Verify(ctx); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if s.AppliedAt == nil || s.Modified || s.Unknown {
+			t.Errorf("migration %04d_%s: %+v", s.Version, s.Name, s)
+		}
+	}
+	for _, table := range tables {
+		if !db.Migrator().HasTable(table) {
+			t.Errorf("table %s is missing after Up", table)
+		}
+	}
+}
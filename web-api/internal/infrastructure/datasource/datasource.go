@@ -0,0 +1,151 @@
+// Package datasource builds the domain repositories from the source
+// configured for each resource, so that one binary can run against the
+// database, JSONPlaceholder, process memory or a mix of them.
+package datasource
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
+	dbRepository "github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/memory"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/gateway/fallback"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/gateway/jsonplaceholder"
+	"gorm.io/gorm"
+)
+
+// Sources of a resource.
+const (
+	// Postgres stores the resource in the database.
+	Postgres = "postgres"
+	// JSONPlaceholder reads the resource from JSONPlaceholder. Writes fail
+	// with jsonplaceholder.ErrReadOnly.
+	JSONPlaceholder = "jsonplaceholder"
+	// Memory keeps the resource in process memory until restart.
+	Memory = "memory"
+	// Fallback stores the resource in the database and reads from
+	// JSONPlaceholder as the fallback options say.
+	Fallback = "fallback"
+)
+
+// NeedsDatabase reports whether any of sources is kept in the database.
+func NeedsDatabase(sources ...string) bool {
+	for _, source := range sources {
+		if source == Postgres || source == Fallback {
+			return true
+		}
+	}
+	return false
+}
+
+type Options struct {
+	// Users and Posts are the sources of the two resources.
+	Users string
+	Posts string
+	// UserFallback and PostFallback decide when the fallback source reads
+	// from JSONPlaceholder. With write-back the records it finds after a
+	// miss are imported into the database.
+	UserFallback  fallback.Policy
+	UserWriteBack bool
+	PostFallback  fallback.Policy
+	PostWriteBack bool
+	// DB is required by the postgres and fallback sources. API keys,
+	// refresh tokens and identities are kept in it when it is set and in
+	// memory otherwise.
+	DB          *gorm.DB
+	UserGateway *jsonplaceholder.UserGateway
+	PostGateway *jsonplaceholder.PostGateway
+	Logger      *slog.Logger
+}
+
+// Repositories are the repositories the API runs on.
+type Repositories struct {
+	Users repository.UserRepository
+	// Accounts are the users that authentication resolves: registration,
+	// login, SSO and the owners of API keys. It is never a gateway, whose
+	// users carry foreign IDs, but the database, or memory without one. It
+	// holds the same records as Users for the postgres and memory sources,
+	// and the local records of the fallback source.
+	Accounts      repository.UserRepository
+	Posts         repository.PostRepository
+	APIKeys       repository.APIKeyRepository
+	RefreshTokens repository.RefreshTokenRepository
+	Identities    repository.IdentityRepository
+}
+
+// New builds the repositories for opts.
+func New(opts Options) (*Repositories, error) {
+	if NeedsDatabase(opts.Users, opts.Posts) && opts.DB == nil {
+		return nil, fmt.Errorf("datasource: the %s and %s sources require a database", Postgres, Fallback)
+	}
+
+	repos := &Repositories{}
+	var err error
+	if repos.Users, repos.Accounts, err = newUserRepositories(opts); err != nil {
+		return nil, err
+	}
+	if repos.Posts, err = newPostRepository(opts, repos.Accounts); err != nil {
+		return nil, err
+	}
+
+	if opts.DB != nil {
+		repos.APIKeys = dbRepository.NewAPIKeyRepository(opts.DB)
+		repos.RefreshTokens = dbRepository.NewRefreshTokenRepository(opts.DB)
+		repos.Identities = dbRepository.NewIdentityRepository(opts.DB)
+	} else {
+		repos.APIKeys = memory.NewAPIKeyRepository()
+		repos.RefreshTokens = memory.NewRefreshTokenRepository()
+		repos.Identities = memory.NewIdentityRepository()
+	}
+	return repos, nil
+}
+
+// newUserRepositories returns the users and accounts repositories.
+func newUserRepositories(opts Options) (users, accounts repository.UserRepository, err error) {
+	switch opts.Users {
+	case Postgres:
+		users := dbRepository.NewUserRepository(opts.DB)
+		return users, users, nil
+	case JSONPlaceholder:
+		if opts.DB != nil {
+			return opts.UserGateway, dbRepository.NewUserRepository(opts.DB), nil
+		}
+		return opts.UserGateway, memory.NewUserRepository(), nil
+	case Memory:
+		users := memory.NewUserRepository()
+		return users, users, nil
+	case Fallback:
+		local := dbRepository.NewUserRepository(opts.DB)
+		fallbackOpts := fallbackOptions(opts, opts.UserFallback, opts.UserWriteBack)
+		return fallback.NewUserRepository(local, opts.UserGateway, fallbackOpts), local, nil
+	default:
+		return nil, nil, fmt.Errorf("datasource: unknown users source %q", opts.Users)
+	}
+}
+
+// newPostRepository returns the posts repository. Posts kept in memory are
+// written by the local accounts.
+func newPostRepository(opts Options, accounts repository.UserRepository) (repository.PostRepository, error) {
+	switch opts.Posts {
+	case Postgres:
+		return dbRepository.NewPostRepository(opts.DB), nil
+	case JSONPlaceholder:
+		return opts.PostGateway, nil
+	case Memory:
+		return memory.NewPostRepository(accounts), nil
+	case Fallback:
+		fallbackOpts := fallbackOptions(opts, opts.PostFallback, opts.PostWriteBack)
+		return fallback.NewPostRepository(dbRepository.NewPostRepository(opts.DB), opts.PostGateway, opts.UserGateway, fallbackOpts), nil
+	default:
+		return nil, fmt.Errorf("datasource: unknown posts source %q", opts.Posts)
+	}
+}
+
+func fallbackOptions(opts Options, policy fallback.Policy, writeBack bool) fallback.Options {
+	fallbackOpts := fallback.Options{Policy: policy, Logger: opts.Logger}
+	if writeBack {
+		fallbackOpts.WriteBack = dbRepository.NewImportRepository(opts.DB)
+	}
+	return fallbackOpts
+}
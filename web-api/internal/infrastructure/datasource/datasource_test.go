@@ -0,0 +1,57 @@
+package datasource
+
+import (
+	"testing"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database/databasetest"
+	dbRepository "github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/memory"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/gateway/fallback"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/gateway/jsonplaceholder"
+)
+
+func TestAccountsAreLocal(t *testing.T) {
+	gateway := jsonplaceholder.NewUserGateway("http://jsonplaceholder.invalid", nil)
+
+	tests := []struct {
+		users    string
+		database bool
+		// local reports whether the accounts are of the wanted store.
+		local func(repository.UserRepository) bool
+		// shared reports whether users and accounts are the same.
+		shared bool
+	}{
+		{Postgres, true, isDatabase, true},
+		{Memory, false, isMemory, true},
+		{Fallback, true, isDatabase, false},
+		{JSONPlaceholder, true, isDatabase, false},
+		{JSONPlaceholder, false, isMemory, false},
+	}
+	for _, tt := range tests {
+		opts := Options{Users: tt.users, Posts: Memory, UserGateway: gateway, UserFallback: fallback.PolicyMiss}
+		if tt.database {
+			opts.DB = databasetest.SQLite(t)
+		}
+		repos, err := New(opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.users, err)
+		}
+		if !tt.local(repos.Accounts) {
+			t.Errorf("%s (database %t): accounts are %T", tt.users, tt.database, repos.Accounts)
+		}
+		if shared := repos.Users == repos.Accounts; shared != tt.shared {
+			t.Errorf("%s: users and accounts shared = %t, want %t", tt.users, shared, tt.shared)
+		}
+	}
+}
+
+func isDatabase(r repository.UserRepository) bool {
+	_, ok := r.(*dbRepository.UserRepository)
+	return ok
+}
+
+func isMemory(r repository.UserRepository) bool {
+	_, ok := r.(*memory.UserRepository)
+	return ok
+}
@@ -0,0 +1,60 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database"
+)
+
+// DatabaseCheck pings the connection pool.
+func DatabaseCheck(db *gorm.DB, timeout time.Duration) Check {
+	return Check{
+		Name:    "database",
+		Timeout: timeout,
+		Run: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
+
+// MigrationsCheck verifies that every migration of this build is applied,
+// so that an instance is not routed to before the schema is current.
+func MigrationsCheck(migrator *database.Migrator, timeout time.Duration) Check {
+	return Check{
+		Name:    "migrations",
+		Timeout: timeout,
+		Run:     migrator.Verify,
+	}
+}
+
+// HTTPCheck requires url to answer with a non 5xx status.
+func HTTPCheck(name, url string, client *http.Client, timeout time.Duration) Check {
+	return Check{
+		Name:    name,
+		Timeout: timeout,
+		Run: func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= http.StatusInternalServerError {
+				return fmt.Errorf("unexpected status %d", resp.StatusCode)
+			}
+			return nil
+		},
+	}
+}
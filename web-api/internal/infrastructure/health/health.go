@@ -0,0 +1,167 @@
+// Package health serves the liveness and readiness probes.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check is a single readiness dependency. Run must honour the deadline of
+// ctx.
+type Check struct {
+	Name    string
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+type CheckResult struct {
+	Status   string `json:"status"`
+	Duration string `json:"duration,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Checker runs the readiness checks. Once Drain is called it reports not
+// ready without running them, so that load balancers stop routing to the
+// instance while in-flight requests finish.
+//
+// The checks run at most once per cache TTL, however often readiness is
+// probed, and their failures are logged.
+type Checker struct {
+	checks   []Check
+	logger   *slog.Logger
+	cacheTTL time.Duration
+	draining atomic.Bool
+
+	// mu is held while the checks run, so that concurrent probes wait for
+	// and share one result.
+	mu        sync.Mutex
+	report    Report
+	checkedAt time.Time
+}
+
+func NewChecker(logger *slog.Logger, cacheTTL time.Duration, checks ...Check) *Checker {
+	return &Checker{checks: checks, logger: logger, cacheTTL: cacheTTL}
+}
+
+// Drain makes readiness fail from now on.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Check returns the result of the checks, running them again when the
+// cached one is older than the cache TTL.
+func (c *Checker) Check(ctx context.Context) Report {
+	if c.draining.Load() {
+		return Report{Status: "draining"}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.checkedAt.IsZero() || time.Since(c.checkedAt) >= c.cacheTTL {
+		// The result is shared, so it must not depend on the probe that
+		// happened to run the checks going away.
+		c.report = c.run(context.WithoutCancel(ctx))
+		c.checkedAt = time.Now()
+	}
+	return c.report
+}
+
+// run runs all checks concurrently, each bounded by its own timeout.
+func (c *Checker) run(ctx context.Context) Report {
+	results := make(map[string]CheckResult, len(c.checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := runCheck(ctx, check)
+			mu.Lock()
+			results[check.Name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: "ok", Checks: results}
+	for name, result := range results {
+		if result.Status != "ok" {
+			report.Status = "unavailable"
+			c.logger.WarnContext(ctx, "Readiness check failed", "check", name, "duration", result.Duration, "error", result.Error)
+		}
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Run(ctx)
+	result := CheckResult{Status: "ok", Duration: time.Since(start).Round(time.Microsecond).String()}
+	if err != nil {
+		result.Status = "failed"
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Liveness reports that the process is serving requests. It checks no
+// dependencies, so that an outage of one does not get the process
+// restarted.
+func (c *Checker) Liveness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, http.StatusOK, Report{Status: "ok"})
+	})
+}
+
+// Readiness reports whether the instance should receive traffic, with
+// whether each check passed. Why a check failed is logged and served by
+// Details only, since errors can reveal hosts, schemas and versions.
+func (c *Checker) Readiness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Check(r.Context())
+		public := Report{Status: report.Status}
+		if report.Checks != nil {
+			public.Checks = make(map[string]CheckResult, len(report.Checks))
+			for name, result := range report.Checks {
+				public.Checks[name] = CheckResult{Status: result.Status}
+			}
+		}
+		writeReport(w, readinessStatus(report), public)
+	})
+}
+
+// Details serves readiness with the duration and error of every check, for
+// the admin listener.
+func (c *Checker) Details() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Check(r.Context())
+		writeReport(w, readinessStatus(report), report)
+	})
+}
+
+func readinessStatus(report Report) int {
+	if report.Status != "ok" {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+func writeReport(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,219 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/health"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func passing(name string) health.Check {
+	return health.Check{Name: name, Timeout: time.Second, Run: func(ctx context.Context) error { return nil }}
+}
+
+func failing(name string) health.Check {
+	return health.Check{Name: name, Timeout: time.Second, Run: func(ctx context.Context) error { return errors.New("down") }}
+}
+
+// hanging waits for its deadline.
+func hanging(name string) health.Check {
+	return health.Check{Name: name, Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+}
+
+func readiness(t *testing.T, checker *health.Checker) (int, health.Report) {
+	t.Helper()
+	return serve(t, checker.Readiness())
+}
+
+func serve(t *testing.T, h http.Handler) (int, health.Report) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var report health.Report
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	return rec.Code, report
+}
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []health.Check
+		want   int
+		status string
+		// failed are the checks expected to fail.
+		failed []string
+	}{
+		{name: "no checks", want: http.StatusOK, status: "ok"},
+		{
+			name:   "all pass",
+			checks: []health.Check{passing("database"), passing("migrations")},
+			want:   http.StatusOK,
+			status: "ok",
+		},
+		{
+			name:   "one fails",
+			checks: []health.Check{passing("database"), failing("jsonplaceholder")},
+			want:   http.StatusServiceUnavailable,
+			status: "unavailable",
+			failed: []string{"jsonplaceholder"},
+		},
+		{
+			name:   "one times out",
+			checks: []health.Check{hanging("database"), passing("migrations")},
+			want:   http.StatusServiceUnavailable,
+			status: "unavailable",
+			failed: []string{"database"},
+		},
+		{
+			name:   "all fail",
+			checks: []health.Check{failing("database"), hanging("migrations")},
+			want:   http.StatusServiceUnavailable,
+			status: "unavailable",
+			failed: []string{"database", "migrations"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, report := readiness(t, health.NewChecker(discard, 0, tt.checks...))
+			if code != tt.want || report.Status != tt.status {
+				t.Fatalf("readiness = %d %q, want %d %q", code, report.Status, tt.want, tt.status)
+			}
+			if len(report.Checks) != len(tt.checks) {
+				t.Fatalf("report has %d checks, want %d", len(report.Checks), len(tt.checks))
+			}
+			failed := make(map[string]bool)
+			for _, name := range tt.failed {
+				failed[name] = true
+			}
+			for _, check := range tt.checks {
+				result := report.Checks[check.Name]
+				want := "ok"
+				if failed[check.Name] {
+					want = "failed"
+				}
+				if result != (health.CheckResult{Status: want}) {
+					t.Errorf("%s = %+v, want only status %q", check.Name, result, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDetails(t *testing.T) {
+	checker := health.NewChecker(discard, 0, passing("database"), failing("jsonplaceholder"))
+
+	code, report := serve(t, checker.Details())
+	if code != http.StatusServiceUnavailable || report.Status != "unavailable" {
+		t.Fatalf("details = %d %q", code, report.Status)
+	}
+	if result := report.Checks["jsonplaceholder"]; result.Status != "failed" || result.Error != "down" || result.Duration == "" {
+		t.Errorf("jsonplaceholder = %+v, want failed with its error and duration", result)
+	}
+}
+
+func TestReadinessCachesResults(t *testing.T) {
+	var runs atomic.Int32
+	checker := health.NewChecker(discard, time.Hour, health.Check{Name: "database", Timeout: time.Second, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+
+	for range 5 {
+		if code, _ := readiness(t, checker); code != http.StatusOK {
+			t.Fatalf("readiness = %d, want %d", code, http.StatusOK)
+		}
+	}
+	serve(t, checker.Details())
+	if runs.Load() != 1 {
+		t.Errorf("checks ran %d times, want once", runs.Load())
+	}
+
+	// Draining is reported at once, whatever is cached.
+	checker.Drain()
+	if code, report := readiness(t, checker); code != http.StatusServiceUnavailable || report.Status != "draining" {
+		t.Errorf("readiness after Drain = %d %q", code, report.Status)
+	}
+}
+
+func TestReadinessRunsChecksConcurrently(t *testing.T) {
+	checks := []health.Check{hanging("a"), hanging("b"), hanging("c"), hanging("d")}
+	for i := range checks {
+		checks[i].Timeout = 50 * time.Millisecond
+	}
+
+	start := time.Now()
+	readiness(t, health.NewChecker(discard, 0, checks...))
+	if elapsed := time.Since(start); elapsed >= 150*time.Millisecond {
+		t.Errorf("readiness took %v, want about one timeout", elapsed)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	var runs atomic.Int32
+	checker := health.NewChecker(discard, 0, health.Check{Name: "database", Timeout: time.Second, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+
+	if code, _ := readiness(t, checker); code != http.StatusOK {
+		t.Fatalf("readiness before Drain = %d, want %d", code, http.StatusOK)
+	}
+	checker.Drain()
+	code, report := readiness(t, checker)
+	if code != http.StatusServiceUnavailable || report.Status != "draining" {
+		t.Errorf("readiness after Drain = %d %q, want %d %q", code, report.Status, http.StatusServiceUnavailable, "draining")
+	}
+	if runs.Load() != 1 {
+		t.Errorf("checks ran %d times, want only before Drain", runs.Load())
+	}
+
+	// Liveness does not depend on draining.
+	rec := httptest.NewRecorder()
+	checker.Liveness().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("liveness after Drain = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestHTTPCheck(t *testing.T) {
+	tests := []struct {
+		status int
+		ok     bool
+	}{
+		{http.StatusOK, true},
+		{http.StatusNotFound, true},
+		{http.StatusInternalServerError, false},
+		{http.StatusServiceUnavailable, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			check := health.HTTPCheck("upstream", server.URL, server.Client(), time.Second)
+			if err := check.Run(context.Background()); (err == nil) != tt.ok {
+				t.Errorf("Run = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
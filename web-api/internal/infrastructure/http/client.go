@@ -3,10 +3,15 @@ package http
 import (
 	"net/http"
 	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
+// NewHTTPClient returns a client whose calls are traced as client spans and
+// carry the W3C trace context of the request that made them.
 func NewHTTPClient() *http.Client {
 	return &http.Client{
-		Timeout: 30 * time.Second,
+		Timeout:   30 * time.Second,
+		Transport: otelhttp.NewTransport(http.DefaultTransport),
 	}
-}
\ No newline at end of file
+}
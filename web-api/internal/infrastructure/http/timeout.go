@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// TimeoutTransport bounds each request, including reading the response
+// body, like http.Client.Timeout does, but the timeout can be changed while
+// requests are in flight. Requests already started keep their deadline.
+type TimeoutTransport struct {
+	next    http.RoundTripper
+	timeout atomic.Int64
+}
+
+func NewTimeoutTransport(next http.RoundTripper, timeout time.Duration) *TimeoutTransport {
+	t := &TimeoutTransport{next: next}
+	t.SetTimeout(timeout)
+	return t
+}
+
+// SetTimeout sets the timeout of subsequent requests. Zero means no timeout.
+func (t *TimeoutTransport) SetTimeout(timeout time.Duration) {
+	t.timeout.Store(int64(timeout))
+}
+
+func (t *TimeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	timeout := time.Duration(t.timeout.Load())
+	if timeout <= 0 {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelBody releases the deadline of a request once its body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
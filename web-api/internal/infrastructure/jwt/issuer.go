@@ -0,0 +1,63 @@
+package jwt
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+var ErrNoSigningKey = errors.New("no token signing key configured")
+
+// Issuer signs access tokens with the active local signing key.
+type Issuer struct {
+	keys     *KeySet
+	issuer   string
+	audience string
+	ttl      time.Duration
+}
+
+func NewIssuer(keys *KeySet, issuer, audience string, ttl time.Duration) *Issuer {
+	return &Issuer{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+		ttl:      ttl,
+	}
+}
+
+func (i *Issuer) IssueAccessToken(userID valueobject.UserID, role valueobject.Role, scopes []string) (string, time.Time, error) {
+	key := i.keys.signingKey()
+	if key == nil {
+		return "", time.Time{}, ErrNoSigningKey
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(i.ttl)
+	claims := Claims{
+		RegisteredClaims: gojwt.RegisteredClaims{
+			Issuer:    i.issuer,
+			Subject:   userID.String(),
+			IssuedAt:  gojwt.NewNumericDate(now),
+			NotBefore: gojwt.NewNumericDate(now),
+			ExpiresAt: gojwt.NewNumericDate(expiresAt),
+		},
+		Scope: strings.Join(scopes, " "),
+		Role:  role.String(),
+	}
+	if i.audience != "" {
+		claims.Audience = gojwt.ClaimStrings{i.audience}
+	}
+
+	token := gojwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+
+	signed, err := token.SignedString(key.key)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
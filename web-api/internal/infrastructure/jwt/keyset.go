@@ -0,0 +1,324 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+// KeySet holds the keys used to verify and sign tokens. Verification keys
+// come from a JWKS file (or a directory of them); signing keys are PEM
+// encoded private keys from a file or directory. The public half of every
+// signing key is trusted as well, so tokens signed with a rotated-out key
+// remain valid until the key file is removed.
+type KeySet struct {
+	jwksPath    string
+	signingPath string
+
+	mu     sync.RWMutex
+	verify map[string]crypto.PublicKey
+	// own are the key IDs of the signing keys, whose tokens this service
+	// issued itself.
+	own      map[string]bool
+	signing  *signingKey
+	issued   []JWK
+	modTimes map[string]time.Time
+}
+
+type signingKey struct {
+	kid    string
+	key    crypto.Signer
+	method gojwt.SigningMethod
+}
+
+func NewKeySet(jwksPath, signingPath string) (*KeySet, error) {
+	ks := &KeySet{
+		jwksPath:    jwksPath,
+		signingPath: signingPath,
+	}
+	if err := ks.Reload(); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Reload reads all key files again. The current keys are kept if any file
+// fails to load.
+func (ks *KeySet) Reload() error {
+	modTimes := make(map[string]time.Time)
+	verify := make(map[string]crypto.PublicKey)
+
+	jwksFiles, err := listKeyFiles(ks.jwksPath, ".json", modTimes)
+	if err != nil {
+		return err
+	}
+	for _, path := range jwksFiles {
+		keys, err := readJWKS(path)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		for _, k := range keys.Keys {
+			if k.Use != "" && k.Use != "sig" {
+				continue
+			}
+			pub, err := k.PublicKey()
+			if err != nil {
+				return fmt.Errorf("failed to load key %q from %s: %w", k.Kid, path, err)
+			}
+			kid := k.Kid
+			if kid == "" {
+				kid = k.Thumbprint()
+			}
+			verify[kid] = pub
+		}
+	}
+
+	pemFiles, err := listKeyFiles(ks.signingPath, ".pem", modTimes)
+	if err != nil {
+		return err
+	}
+	// The most recently written key file is the active signing key.
+	sort.SliceStable(pemFiles, func(i, j int) bool {
+		return modTimes[pemFiles[i]].After(modTimes[pemFiles[j]])
+	})
+	own := make(map[string]bool)
+	var active *signingKey
+	var issued []JWK
+	for _, path := range pemFiles {
+		key, err := readPrivateKey(path)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		jwk, err := NewJWK(key.Public())
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		verify[jwk.Kid] = key.Public()
+		own[jwk.Kid] = true
+		issued = append(issued, jwk)
+		if active == nil {
+			active = &signingKey{kid: jwk.Kid, key: key, method: signingMethod(key)}
+		}
+	}
+
+	if len(verify) == 0 {
+		return errors.New("no token verification keys found")
+	}
+
+	ks.mu.Lock()
+	ks.verify = verify
+	ks.own = own
+	ks.signing = active
+	ks.issued = issued
+	ks.modTimes = modTimes
+	ks.mu.Unlock()
+	return nil
+}
+
+// Watch polls the key files every interval and reloads them when they
+// change, until ctx is cancelled.
+func (ks *KeySet) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !ks.changed() {
+				continue
+			}
+			if err := ks.Reload(); err != nil {
+				log.Println("Failed to reload JWT keys, keeping current keys:", err)
+				continue
+			}
+			log.Println("JWT keys reloaded")
+		}
+	}
+}
+
+func (ks *KeySet) changed() bool {
+	modTimes := make(map[string]time.Time)
+	if _, err := listKeyFiles(ks.jwksPath, ".json", modTimes); err != nil {
+		return true
+	}
+	if _, err := listKeyFiles(ks.signingPath, ".pem", modTimes); err != nil {
+		return true
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if len(modTimes) != len(ks.modTimes) {
+		return true
+	}
+	for path, t := range modTimes {
+		if prev, ok := ks.modTimes[path]; !ok || !prev.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// publicKey returns the key that verifies tokens with the key ID kid, and
+// whether it is one of the signing keys.
+func (ks *KeySet) publicKey(kid string) (key crypto.PublicKey, own bool, err error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	if kid == "" {
+		if len(ks.verify) != 1 {
+			return nil, false, errors.New("token has no key ID")
+		}
+		for id, key := range ks.verify {
+			return key, ks.own[id], nil
+		}
+	}
+	key, ok := ks.verify[kid]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown key ID %q", kid)
+	}
+	return key, ks.own[kid], nil
+}
+
+func (ks *KeySet) signingKey() *signingKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.signing
+}
+
+// CanSign reports whether a local signing key is configured.
+func (ks *KeySet) CanSign() bool {
+	return ks.signingKey() != nil
+}
+
+// PublicJWKS returns the JSON encoded public keys of the local signing keys,
+// as served from /.well-known/jwks.json.
+func (ks *KeySet) PublicJWKS() ([]byte, error) {
+	ks.mu.RLock()
+	keys := JWKS{Keys: ks.issued}
+	ks.mu.RUnlock()
+
+	if keys.Keys == nil {
+		keys.Keys = []JWK{}
+	}
+	return json.Marshal(keys)
+}
+
+// listKeyFiles returns path itself if it is a file, or the files with the
+// given extension if it is a directory. Modification times are recorded in
+// modTimes.
+func listKeyFiles(path, ext string, modTimes map[string]time.Time) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		modTimes[path] = info.ModTime()
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ext) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		file := filepath.Join(path, entry.Name())
+		modTimes[file] = info.ModTime()
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// readJWKS accepts either a key set or a single key.
+func readJWKS(path string) (JWKS, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return JWKS{}, err
+	}
+
+	var keys JWKS
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return JWKS{}, err
+	}
+	if keys.Keys != nil {
+		return keys, nil
+	}
+
+	var key JWK
+	if err := json.Unmarshal(data, &key); err != nil {
+		return JWKS{}, err
+	}
+	return JWKS{Keys: []JWK{key}}, nil
+}
+
+func readPrivateKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var key any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
+func signingMethod(key crypto.Signer) gojwt.SigningMethod {
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		return gojwt.SigningMethodEdDSA
+	}
+	return gojwt.SigningMethodRS256
+}
@@ -0,0 +1,154 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+func publishedKeyIDs(t *testing.T, keys *KeySet) []string {
+	t.Helper()
+
+	data, err := keys.PublicJWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw struct {
+		Keys []map[string]any `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	var kids []string
+	for _, k := range raw.Keys {
+		if _, ok := k["d"]; ok {
+			t.Errorf("JWKS publishes the private key %v", k["kid"])
+		}
+		kid, _ := k["kid"].(string)
+		kids = append(kids, kid)
+	}
+	return kids
+}
+
+func TestKeySetRotation(t *testing.T) {
+	keys, dir := newTestKeySet(t)
+	issuer := NewIssuer(keys, "api", "api", time.Minute)
+	verifier := NewVerifier(keys, nil, "api", "api", 0)
+	userID, _ := valueobject.NewUserID(1)
+
+	// Age the first key, as if it had been written before the rotation.
+	oldPath := filepath.Join(dir, "old.pem")
+	if err := os.Rename(filepath.Join(dir, "signing.pem"), oldPath); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(oldPath, past, past); err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	oldKid := keys.signingKey().kid
+	oldToken, _, err := issuer.IssueAccessToken(userID, valueobject.RoleUser, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := WriteSigningKey(dir); err != nil {
+		t.Fatal(err)
+	}
+	if !keys.changed() {
+		t.Error("changed() = false after a key was written")
+	}
+	if err := keys.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	newKid := keys.signingKey().kid
+	if newKid == oldKid {
+		t.Fatal("the newest key file did not become the signing key")
+	}
+	newToken, _, err := issuer.IssueAccessToken(userID, valueobject.RoleUser, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		if _, err := verifier.Verify(token); err != nil {
+			t.Errorf("%s token: %v", name, err)
+		}
+	}
+	if kids := publishedKeyIDs(t, keys); len(kids) != 2 || kids[0] != newKid || kids[1] != oldKid {
+		t.Errorf("published keys = %v, want [%s %s]", kids, newKid, oldKid)
+	}
+
+	// A key file that does not load keeps the current keys.
+	if err := os.WriteFile(filepath.Join(dir, "broken.pem"), []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.Reload(); err == nil {
+		t.Fatal("Reload accepted a broken key file")
+	}
+	if _, err := verifier.Verify(oldToken); err != nil {
+		t.Errorf("old token after a failed reload: %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, "broken.pem")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing the old key file retires its tokens.
+	if err := os.Remove(oldPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Verify(oldToken); err == nil {
+		t.Error("token of a removed key verified")
+	}
+	if _, err := verifier.Verify(newToken); err != nil {
+		t.Errorf("new token: %v", err)
+	}
+	if kids := publishedKeyIDs(t, keys); len(kids) != 1 || kids[0] != newKid {
+		t.Errorf("published keys = %v, want [%s]", kids, newKid)
+	}
+}
+
+func TestKeySetVerificationOnly(t *testing.T) {
+	path, priv, kid := writeJWKS(t)
+
+	keys, err := NewKeySet(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys.CanSign() {
+		t.Error("CanSign() = true without a signing key")
+	}
+	if _, _, err := NewIssuer(keys, "api", "api", time.Minute).IssueAccessToken(valueobject.UserID{}, valueobject.RoleUser, nil); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("IssueAccessToken error = %v, want ErrNoSigningKey", err)
+	}
+	// Trusted keys are not published as ours.
+	if kids := publishedKeyIDs(t, keys); len(kids) != 0 {
+		t.Errorf("published keys = %v, want none", kids)
+	}
+
+	token := sign(t, gojwt.SigningMethodEdDSA, priv, kid, Claims{RegisteredClaims: gojwt.RegisteredClaims{
+		Issuer:    "idp",
+		Subject:   "external",
+		ExpiresAt: gojwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}})
+	if _, err := NewVerifier(keys, nil, "idp", "", 0).Verify(token); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+}
+
+func TestNewKeySetWithoutKeys(t *testing.T) {
+	if _, err := NewKeySet("", t.TempDir()); err == nil {
+		t.Error("NewKeySet accepted an empty key directory")
+	}
+}
@@ -0,0 +1,103 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/auth"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+type Claims struct {
+	gojwt.RegisteredClaims
+	Scope string `json:"scope,omitempty"`
+	Role  string `json:"role,omitempty"`
+}
+
+var ErrUnknownUser = errors.New("token subject is not a user")
+
+type Verifier struct {
+	keys   *KeySet
+	users  repository.UserRepository
+	parser *gojwt.Parser
+}
+
+// NewVerifier returns a verifier of the tokens signed by keys. The tokens
+// of the signing keys are resolved to the local users in users.
+func NewVerifier(keys *KeySet, users repository.UserRepository, issuer, audience string, clockSkew time.Duration) *Verifier {
+	opts := []gojwt.ParserOption{
+		gojwt.WithValidMethods([]string{gojwt.SigningMethodRS256.Alg(), gojwt.SigningMethodEdDSA.Alg()}),
+		gojwt.WithExpirationRequired(),
+		gojwt.WithIssuedAt(),
+		gojwt.WithLeeway(clockSkew),
+	}
+	if issuer != "" {
+		opts = append(opts, gojwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		opts = append(opts, gojwt.WithAudience(audience))
+	}
+
+	return &Verifier{
+		keys:   keys,
+		users:  users,
+		parser: gojwt.NewParser(opts...),
+	}
+}
+
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	claims, _, err := v.verify(token)
+	return claims, err
+}
+
+// verify returns the claims of token and whether one of the signing keys
+// signed it.
+func (v *Verifier) verify(token string) (*Claims, bool, error) {
+	var claims Claims
+	var own bool
+	_, err := v.parser.ParseWithClaims(token, &claims, func(t *gojwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ownKey, err := v.keys.publicKey(kid)
+		own = ownKey
+		return key, err
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid token: %w", err)
+	}
+	return &claims, own, nil
+}
+
+// Authenticate verifies a bearer token and returns its principal. Tokens
+// signed by the signing keys act as the user of their subject, with the
+// role the user has now rather than the one in the token. Tokens of
+// trusted external keys act as no local user and have no role, whatever
+// they claim.
+func (v *Verifier) Authenticate(ctx context.Context, token string) (*auth.Principal, error) {
+	claims, own, err := v.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	scopes := strings.Fields(claims.Scope)
+	if !own {
+		return auth.NewPrincipal(claims.Subject, valueobject.UserID{}, valueobject.Role{}, auth.MethodJWT, scopes), nil
+	}
+
+	userID, err := valueobject.NewUserIDFromString(claims.Subject)
+	if err != nil {
+		return nil, ErrUnknownUser
+	}
+	user, err := v.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("failed to verify token")
+	}
+	if user == nil {
+		return nil, ErrUnknownUser
+	}
+	return auth.NewPrincipal(claims.Subject, user.ID(), user.Role(), auth.MethodJWT, scopes), nil
+}
@@ -0,0 +1,301 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/memory"
+)
+
+// newTestKeySet returns a key set with one signing key in a new directory.
+func newTestKeySet(t *testing.T) (*KeySet, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	if _, err := WriteSigningKey(dir); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := NewKeySet("", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys, dir
+}
+
+func sign(t *testing.T, method gojwt.SigningMethod, key any, kid string, claims Claims) string {
+	t.Helper()
+
+	token := gojwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestVerifier(t *testing.T) {
+	keys, _ := newTestKeySet(t)
+	active := keys.signingKey()
+	verifier := NewVerifier(keys, nil, "api", "api", time.Minute)
+
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	claims := func(change func(*Claims)) Claims {
+		c := Claims{RegisteredClaims: gojwt.RegisteredClaims{
+			Issuer:    "api",
+			Subject:   "1",
+			Audience:  gojwt.ClaimStrings{"api"},
+			IssuedAt:  gojwt.NewNumericDate(now),
+			ExpiresAt: gojwt.NewNumericDate(now.Add(time.Minute)),
+		}}
+		if change != nil {
+			change(&c)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{
+			name:  "valid",
+			token: sign(t, active.method, active.key, active.kid, claims(nil)),
+			valid: true,
+		},
+		{
+			// The only key is used for a token without a key ID.
+			name:  "no key ID",
+			token: sign(t, active.method, active.key, "", claims(nil)),
+			valid: true,
+		},
+		{
+			name: "expired within clock skew",
+			token: sign(t, active.method, active.key, active.kid, claims(func(c *Claims) {
+				c.ExpiresAt = gojwt.NewNumericDate(now.Add(-30 * time.Second))
+			})),
+			valid: true,
+		},
+		{
+			name: "expired",
+			token: sign(t, active.method, active.key, active.kid, claims(func(c *Claims) {
+				c.ExpiresAt = gojwt.NewNumericDate(now.Add(-2 * time.Minute))
+			})),
+		},
+		{
+			name: "no expiry",
+			token: sign(t, active.method, active.key, active.kid, claims(func(c *Claims) {
+				c.ExpiresAt = nil
+			})),
+		},
+		{
+			name: "issued in the future",
+			token: sign(t, active.method, active.key, active.kid, claims(func(c *Claims) {
+				c.IssuedAt = gojwt.NewNumericDate(now.Add(2 * time.Minute))
+			})),
+		},
+		{
+			name: "wrong audience",
+			token: sign(t, active.method, active.key, active.kid, claims(func(c *Claims) {
+				c.Audience = gojwt.ClaimStrings{"other"}
+			})),
+		},
+		{
+			name: "no audience",
+			token: sign(t, active.method, active.key, active.kid, claims(func(c *Claims) {
+				c.Audience = nil
+			})),
+		},
+		{
+			name: "wrong issuer",
+			token: sign(t, active.method, active.key, active.kid, claims(func(c *Claims) {
+				c.Issuer = "other"
+			})),
+		},
+		{
+			name:  "unknown key ID",
+			token: sign(t, active.method, active.key, "unknown", claims(nil)),
+		},
+		{
+			name:  "signed by another key",
+			token: sign(t, gojwt.SigningMethodEdDSA, otherKey, active.kid, claims(nil)),
+		},
+		{
+			// A public key must not be usable as an HMAC secret.
+			name:  "HS256",
+			token: sign(t, gojwt.SigningMethodHS256, []byte(active.key.Public().(ed25519.PublicKey)), active.kid, claims(nil)),
+		},
+		{
+			name:  "none",
+			token: sign(t, gojwt.SigningMethodNone, gojwt.UnsafeAllowNoneSignatureType, active.kid, claims(nil)),
+		},
+		{
+			name:  "malformed",
+			token: "not.a.token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := verifier.Verify(tt.token)
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("Verify: %v", err)
+				}
+				if got.Subject != "1" {
+					t.Errorf("subject = %q, want %q", got.Subject, "1")
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Verify accepted the token")
+			}
+		})
+	}
+}
+
+// writeJWKS writes the public half of a new key to a JWKS file and returns
+// the file, the private key and its key ID.
+func writeJWKS(t *testing.T) (string, ed25519.PrivateKey, string) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk, err := NewJWK(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(JWKS{Keys: []JWK{jwk}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path, priv, jwk.Kid
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	jwksPath, externalKey, externalKid := writeJWKS(t)
+	dir := t.TempDir()
+	if _, err := WriteSigningKey(dir); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := NewKeySet(jwksPath, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	own := keys.signingKey()
+
+	users := memory.NewUserRepository()
+	save := func(username string, role valueobject.Role) *entity.User {
+		t.Helper()
+		email, _ := valueobject.NewEmail(username + "@example.com")
+		user := entity.NewUser(valueobject.UserID{}, username, username, email)
+		user.SetRole(role)
+		saved, err := users.Save(ctx, user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return saved
+	}
+	admin := save("admin", valueobject.RoleAdmin)
+	member := save("member", valueobject.RoleUser)
+	verifier := NewVerifier(keys, users, "api", "api", 0)
+
+	claims := func(subject, role string) Claims {
+		now := time.Now()
+		return Claims{
+			RegisteredClaims: gojwt.RegisteredClaims{
+				Issuer:    "api",
+				Subject:   subject,
+				Audience:  gojwt.ClaimStrings{"api"},
+				IssuedAt:  gojwt.NewNumericDate(now),
+				ExpiresAt: gojwt.NewNumericDate(now.Add(time.Minute)),
+			},
+			Scope: "posts:read",
+			Role:  role,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		wantErr  bool
+		wantUser valueobject.UserID
+		wantRole valueobject.Role
+	}{
+		{
+			name:     "own token",
+			token:    sign(t, own.method, own.key, own.kid, claims(admin.ID().String(), "admin")),
+			wantUser: admin.ID(),
+			wantRole: valueobject.RoleAdmin,
+		},
+		{
+			// The role is the user's current one, so a demoted user
+			// loses the old role before the token expires.
+			name:     "own token with a stale role",
+			token:    sign(t, own.method, own.key, own.kid, claims(member.ID().String(), "admin")),
+			wantUser: member.ID(),
+			wantRole: valueobject.RoleUser,
+		},
+		{
+			name:    "own token of an unknown user",
+			token:   sign(t, own.method, own.key, own.kid, claims("9999", "user")),
+			wantErr: true,
+		},
+		{
+			name:    "own token of a non-numeric subject",
+			token:   sign(t, own.method, own.key, own.kid, claims("service", "user")),
+			wantErr: true,
+		},
+		{
+			name:  "external token claiming admin",
+			token: sign(t, gojwt.SigningMethodEdDSA, externalKey, externalKid, claims(admin.ID().String(), "admin")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := verifier.Authenticate(ctx, tt.token)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Authenticate = %+v, want an error", principal)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate: %v", err)
+			}
+			if principal.UserID() != tt.wantUser || principal.Role() != tt.wantRole {
+				t.Errorf("principal = user %v role %q, want user %v role %q",
+					principal.UserID(), principal.Role(), tt.wantUser, tt.wantRole)
+			}
+			if !principal.HasScope("posts:read") || principal.HasScope("users:read") {
+				t.Errorf("scopes = %v, want [posts:read]", principal.Scopes())
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Package lifecycle runs the servers and background workers of the process
+// and shuts them down in order when it is asked to stop.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Server is a listener that serves until Shutdown is called. Start returns
+// nil after a graceful shutdown.
+type Server interface {
+	Start() error
+	Shutdown(ctx context.Context) error
+}
+
+type closer struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// App owns the lifecycle of the process. Shutdown happens in this order:
+//
+//  1. drain hooks run, e.g. to fail readiness;
+//  2. after the drain delay the servers stop accepting and in-flight
+//     requests finish, bounded by the shutdown timeout;
+//  3. background workers are cancelled and awaited, within the same
+//     timeout;
+//  4. closers run in reverse registration order, each within a close
+//     timeout of its own, so that resources opened last, e.g. the
+//     database, close before those they depend on, e.g. tracing.
+type App struct {
+	logger          *slog.Logger
+	drainDelay      time.Duration
+	shutdownTimeout time.Duration
+	closeTimeout    time.Duration
+
+	servers    []Server
+	drainHooks []func()
+	closers    []closer
+
+	workerCtx   context.Context
+	stopWorkers context.CancelFunc
+	workers     sync.WaitGroup
+}
+
+func New(logger *slog.Logger, drainDelay, shutdownTimeout, closeTimeout time.Duration) *App {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &App{
+		logger:          logger,
+		drainDelay:      drainDelay,
+		shutdownTimeout: shutdownTimeout,
+		closeTimeout:    closeTimeout,
+		workerCtx:       ctx,
+		stopWorkers:     cancel,
+	}
+}
+
+func (a *App) AddServer(s Server) {
+	a.servers = append(a.servers, s)
+}
+
+// Go starts a background worker. fn must return once ctx is cancelled.
+func (a *App) Go(name string, fn func(ctx context.Context)) {
+	a.workers.Add(1)
+	go func() {
+		defer a.workers.Done()
+		fn(a.workerCtx)
+		a.logger.Debug("worker stopped", "worker", name)
+	}()
+}
+
+// OnDrain registers fn to run as soon as shutdown begins.
+func (a *App) OnDrain(fn func()) {
+	a.drainHooks = append(a.drainHooks, fn)
+}
+
+// OnClose registers fn to run after servers and workers have stopped.
+// Closers run in reverse registration order.
+func (a *App) OnClose(name string, fn func(ctx context.Context) error) {
+	a.closers = append(a.closers, closer{name: name, fn: fn})
+}
+
+// Run starts the servers and blocks until ctx is cancelled or a server
+// fails, then shuts everything down. It returns the error of the failed
+// server, joined with any error during shutdown.
+func (a *App) Run(ctx context.Context) error {
+	serverErr := make(chan error, len(a.servers))
+	for _, s := range a.servers {
+		go func() {
+			if err := s.Start(); err != nil {
+				serverErr <- err
+			}
+		}()
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		a.logger.Info("shutdown requested")
+	case runErr = <-serverErr:
+		a.logger.Error("server failed, shutting down", "error", runErr)
+	}
+
+	return errors.Join(runErr, a.shutdown())
+}
+
+func (a *App) shutdown() error {
+	for _, fn := range a.drainHooks {
+		fn()
+	}
+	if a.drainDelay > 0 {
+		a.logger.Info("draining", "delay", a.drainDelay)
+		time.Sleep(a.drainDelay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, s := range a.servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.Shutdown(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("server shutdown: %w", err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	a.logger.Info("servers stopped")
+
+	a.stopWorkers()
+	done := make(chan struct{})
+	go func() {
+		a.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		a.logger.Info("workers stopped")
+	case <-ctx.Done():
+		errs = append(errs, errors.New("workers did not stop before the shutdown deadline"))
+	}
+
+	for _, c := range slices.Backward(a.closers) {
+		if err := a.close(c); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+			continue
+		}
+		a.logger.Info("closed", "resource", c.name)
+	}
+
+	return errors.Join(errs...)
+}
+
+// close runs c with a deadline of its own, as the shutdown timeout may be
+// used up by the time it runs.
+func (a *App) close(c closer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.closeTimeout)
+	defer cancel()
+	return c.fn(ctx)
+}
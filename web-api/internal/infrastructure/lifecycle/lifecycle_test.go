@@ -0,0 +1,42 @@
+package lifecycle
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestClosersRunInReverseWithTheirOwnDeadline(t *testing.T) {
+	app := New(slog.New(slog.NewTextHandler(io.Discard, nil)), 0, 10*time.Millisecond, time.Minute)
+
+	// The worker ignores cancellation and uses up the shutdown timeout.
+	stop := make(chan struct{})
+	defer close(stop)
+	app.Go("stuck", func(context.Context) { <-stop })
+
+	var order []string
+	for _, name := range []string{"tracing", "database"} {
+		app.OnClose(name, func(ctx context.Context) error {
+			order = append(order, name)
+			if err := ctx.Err(); err != nil {
+				t.Errorf("closer %s got a done context: %v", name, err)
+			}
+			if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) < 30*time.Second {
+				t.Errorf("closer %s deadline = %v, %v, want its own close timeout", name, deadline, ok)
+			}
+			return nil
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := app.Run(ctx); err == nil {
+		t.Error("Run did not report the stuck worker")
+	}
+	if want := []string{"database", "tracing"}; !slices.Equal(order, want) {
+		t.Errorf("closers ran in order %v, want %v", order, want)
+	}
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+type APIKeyRepository struct {
+	mu   sync.RWMutex
+	keys map[string]entity.APIKey
+}
+
+func NewAPIKeyRepository() *APIKeyRepository {
+	return &APIKeyRepository{keys: make(map[string]entity.APIKey)}
+}
+
+func (r *APIKeyRepository) FindByID(ctx context.Context, id string) (*entity.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, nil
+	}
+	return &key, nil
+}
+
+// FindByUserID returns the keys of the user, oldest first.
+func (r *APIKeyRepository) FindByUserID(ctx context.Context, userID valueobject.UserID) ([]*entity.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := []*entity.APIKey{}
+	for _, key := range r.keys {
+		if key.UserID() == userID {
+			keys = append(keys, &key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b *entity.APIKey) int {
+		return a.CreatedAt().Compare(b.CreatedAt())
+	})
+	return keys, nil
+}
+
+func (r *APIKeyRepository) Save(ctx context.Context, key *entity.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[key.ID()]; ok {
+		return fmt.Errorf("%w: api key %q", ErrDuplicate, key.ID())
+	}
+	r.keys[key.ID()] = *key
+	return nil
+}
+
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return nil
+	}
+	r.keys[id] = *entity.NewAPIKey(key.ID(), key.UserID(), key.Name(), key.KeyHash(), key.Scopes(), key.ExpiresAt(), usedAt, key.CreatedAt())
+	return nil
+}
+
+func (r *APIKeyRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.keys, id)
+	return nil
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+)
+
+type IdentityRepository struct {
+	mu         sync.RWMutex
+	identities map[identityKey]entity.Identity
+}
+
+type identityKey struct {
+	issuer  string
+	subject string
+}
+
+func NewIdentityRepository() *IdentityRepository {
+	return &IdentityRepository{identities: make(map[identityKey]entity.Identity)}
+}
+
+func (r *IdentityRepository) FindBySubject(ctx context.Context, issuer, subject string) (*entity.Identity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	identity, ok := r.identities[identityKey{issuer: issuer, subject: subject}]
+	if !ok {
+		return nil, nil
+	}
+	return &identity, nil
+}
+
+func (r *IdentityRepository) Save(ctx context.Context, identity *entity.Identity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := identityKey{issuer: identity.Issuer(), subject: identity.Subject()}
+	if _, ok := r.identities[key]; ok {
+		return fmt.Errorf("%w: identity %s %s", ErrDuplicate, identity.Issuer(), identity.Subject())
+	}
+	r.identities[key] = *identity
+	return nil
+}
@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+// ErrUnknownAuthor is returned for posts whose author is not among the
+// users, which the database prevents with a foreign key.
+var ErrUnknownAuthor = errors.New("memory: unknown author")
+
+// PostRepository requires the author of every post to exist in users,
+// like the database.
+type PostRepository struct {
+	users repository.UserRepository
+
+	mu     sync.RWMutex
+	posts  map[int]entity.Post
+	nextID int
+}
+
+func NewPostRepository(users repository.UserRepository) *PostRepository {
+	return &PostRepository{
+		users:  users,
+		posts:  make(map[int]entity.Post),
+		nextID: 1,
+	}
+}
+
+func (r *PostRepository) FindAll(ctx context.Context) ([]*entity.Post, error) {
+	return r.filter(func(*entity.Post) bool { return true }), nil
+}
+
+func (r *PostRepository) FindByID(ctx context.Context, id valueobject.PostID) (*entity.Post, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	post, ok := r.posts[id.Value()]
+	if !ok {
+		return nil, nil
+	}
+	return &post, nil
+}
+
+func (r *PostRepository) FindByUserID(ctx context.Context, userID valueobject.UserID) ([]*entity.Post, error) {
+	return r.filter(func(post *entity.Post) bool { return post.UserID() == userID }), nil
+}
+
+func (r *PostRepository) Save(ctx context.Context, post *entity.Post) (*entity.Post, error) {
+	if err := r.checkAuthor(ctx, post); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, _ := valueobject.NewPostID(r.nextID)
+	r.nextID++
+
+	saved := entity.NewPost(id, post.UserID(), post.Title(), post.Body())
+	r.posts[id.Value()] = *saved
+	return saved, nil
+}
+
+// Update replaces the stored post with the same ID. Unknown posts are
+// ignored, as with an UPDATE that matches no row.
+func (r *PostRepository) Update(ctx context.Context, post *entity.Post) error {
+	if err := r.checkAuthor(ctx, post); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.posts[post.ID().Value()]; ok {
+		r.posts[post.ID().Value()] = *post
+	}
+	return nil
+}
+
+func (r *PostRepository) Delete(ctx context.Context, id valueobject.PostID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.posts, id.Value())
+	return nil
+}
+
+func (r *PostRepository) checkAuthor(ctx context.Context, post *entity.Post) error {
+	author, err := r.users.FindByID(ctx, post.UserID())
+	if err != nil {
+		return err
+	}
+	if author == nil {
+		return fmt.Errorf("%w: user %s", ErrUnknownAuthor, post.UserID())
+	}
+	return nil
+}
+
+// filter returns the matching posts ordered by ID.
+func (r *PostRepository) filter(match func(*entity.Post) bool) []*entity.Post {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	posts := []*entity.Post{}
+	for _, id := range slices.Sorted(maps.Keys(r.posts)) {
+		post := r.posts[id]
+		if match(&post) {
+			posts = append(posts, &post)
+		}
+	}
+	return posts
+}
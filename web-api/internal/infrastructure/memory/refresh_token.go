@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+// pruneInterval bounds how often Save looks for expired tokens.
+const pruneInterval = time.Minute
+
+// RefreshTokenRepository keeps tokens by hash. Expired tokens are pruned as
+// new ones are saved. Revoked tokens are kept until they expire, so that a
+// reused token is still found and its family revoked.
+type RefreshTokenRepository struct {
+	mu       sync.Mutex
+	tokens   map[string]entity.RefreshToken
+	hashes   map[string]string
+	prunedAt time.Time
+}
+
+func NewRefreshTokenRepository() *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		tokens: make(map[string]entity.RefreshToken),
+		hashes: make(map[string]string),
+	}
+}
+
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, nil
+	}
+	return &token, nil
+}
+
+func (r *RefreshTokenRepository) Save(ctx context.Context, token *entity.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.prunedAt) >= pruneInterval {
+		r.prune(now)
+	}
+
+	if _, ok := r.hashes[token.ID()]; ok {
+		return fmt.Errorf("%w: refresh token %q", ErrDuplicate, token.ID())
+	}
+	if _, ok := r.tokens[token.TokenHash()]; ok {
+		return fmt.Errorf("%w: refresh token %q", ErrDuplicate, token.ID())
+	}
+	r.tokens[token.TokenHash()] = *token
+	r.hashes[token.ID()] = token.TokenHash()
+	return nil
+}
+
+func (r *RefreshTokenRepository) Revoke(ctx context.Context, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[r.hashes[id]]
+	if !ok || token.IsRevoked() {
+		return false, nil
+	}
+	r.revoke(token, time.Now())
+	return true, nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	r.revokeWhere(func(token *entity.RefreshToken) bool { return token.FamilyID() == familyID })
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID valueobject.UserID) error {
+	r.revokeWhere(func(token *entity.RefreshToken) bool { return token.UserID() == userID })
+	return nil
+}
+
+func (r *RefreshTokenRepository) revokeWhere(match func(*entity.RefreshToken) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range r.tokens {
+		if !token.IsRevoked() && match(&token) {
+			r.revoke(token, now)
+		}
+	}
+}
+
+// revoke stores token as revoked at revokedAt. The caller holds mu.
+func (r *RefreshTokenRepository) revoke(token entity.RefreshToken, revokedAt time.Time) {
+	r.tokens[token.TokenHash()] = *entity.NewRefreshToken(token.ID(), token.UserID(), token.FamilyID(), token.TokenHash(), token.ExpiresAt(), revokedAt)
+}
+
+// prune removes the tokens that expired before now, revoked or not. The
+// caller holds mu.
+func (r *RefreshTokenRepository) prune(now time.Time) {
+	for hash, token := range r.tokens {
+		if token.IsExpired(now) {
+			delete(r.tokens, hash)
+			delete(r.hashes, token.ID())
+		}
+	}
+	r.prunedAt = now
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+func TestRefreshTokens(t *testing.T) {
+	ctx := context.Background()
+	r := NewRefreshTokenRepository()
+	userID, _ := valueobject.NewUserID(1)
+	now := time.Now()
+
+	save := func(id, family, hash string, expiresAt, revokedAt time.Time) {
+		t.Helper()
+		if err := r.Save(ctx, entity.NewRefreshToken(id, userID, family, hash, expiresAt, revokedAt)); err != nil {
+			t.Fatalf("Save %s: %v", id, err)
+		}
+	}
+	save("active", "f1", "hash-active", now.Add(time.Hour), time.Time{})
+	save("expired", "f1", "hash-expired", now.Add(-time.Second), time.Time{})
+	save("revoked", "f2", "hash-revoked", now.Add(time.Hour), now)
+
+	if err := r.Save(ctx, entity.NewRefreshToken("active", userID, "f1", "other", now.Add(time.Hour), time.Time{})); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Save of a duplicate ID = %v, want ErrDuplicate", err)
+	}
+	if err := r.Save(ctx, entity.NewRefreshToken("other", userID, "f1", "hash-active", now.Add(time.Hour), time.Time{})); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Save of a duplicate hash = %v, want ErrDuplicate", err)
+	}
+
+	if token, err := r.FindByHash(ctx, "hash-active"); err != nil || token == nil || token.ID() != "active" {
+		t.Fatalf("FindByHash = %v, %v", token, err)
+	}
+	if revoked, err := r.Revoke(ctx, "active"); !revoked || err != nil {
+		t.Fatalf("Revoke = %v, %v, want true", revoked, err)
+	}
+	if revoked, _ := r.Revoke(ctx, "active"); revoked {
+		t.Error("second Revoke reported true")
+	}
+	if token, _ := r.FindByHash(ctx, "hash-active"); token == nil || !token.IsRevoked() {
+		t.Errorf("token after Revoke = %v, want revoked", token)
+	}
+
+	// Expired tokens go; revoked ones stay until they expire, so that their
+	// reuse is detected.
+	r.mu.Lock()
+	r.prune(now)
+	r.mu.Unlock()
+	if token, _ := r.FindByHash(ctx, "hash-expired"); token != nil {
+		t.Error("expired token was not pruned")
+	}
+	if revoked, _ := r.Revoke(ctx, "expired"); revoked {
+		t.Error("Revoke found the pruned token")
+	}
+	for _, hash := range []string{"hash-active", "hash-revoked"} {
+		if token, _ := r.FindByHash(ctx, hash); token == nil {
+			t.Errorf("unexpired token %s was pruned", hash)
+		}
+	}
+	if len(r.tokens) != len(r.hashes) {
+		t.Errorf("%d tokens but %d IDs", len(r.tokens), len(r.hashes))
+	}
+}
@@ -0,0 +1,18 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository/repositorytest"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/memory"
+)
+
+func TestRepositories(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Subject {
+		users := memory.NewUserRepository()
+		return repositorytest.Subject{
+			Users: users,
+			Posts: memory.NewPostRepository(users),
+		}
+	})
+}
@@ -0,0 +1,136 @@
+// Package memory provides repositories that keep their records in process
+// memory. They are safe for concurrent use and lose everything on restart.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+// ErrDuplicate is returned when a write would break a uniqueness rule that
+// the database enforces with a unique index.
+var ErrDuplicate = repository.ErrDuplicate
+
+// UserRepository enforces unique usernames and emails, like the database.
+type UserRepository struct {
+	mu     sync.RWMutex
+	users  map[int]entity.User
+	nextID int
+}
+
+func NewUserRepository() *UserRepository {
+	return &UserRepository{
+		users:  make(map[int]entity.User),
+		nextID: 1,
+	}
+}
+
+func (r *UserRepository) FindAll(ctx context.Context) ([]*entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*entity.User, 0, len(r.users))
+	for _, id := range slices.Sorted(maps.Keys(r.users)) {
+		user := r.users[id]
+		users = append(users, &user)
+	}
+	return users, nil
+}
+
+func (r *UserRepository) FindByID(ctx context.Context, id valueobject.UserID) (*entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id.Value()]
+	if !ok {
+		return nil, nil
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) FindByEmail(ctx context.Context, email valueobject.Email) (*entity.User, error) {
+	return r.find(func(user *entity.User) bool { return user.Email() == email }), nil
+}
+
+func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return r.find(func(user *entity.User) bool { return user.Username() == username }), nil
+}
+
+func (r *UserRepository) Save(ctx context.Context, user *entity.User) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkUnique(user, 0); err != nil {
+		return nil, err
+	}
+
+	id, _ := valueobject.NewUserID(r.nextID)
+	r.nextID++
+
+	saved := entity.NewUser(id, user.Name(), user.Username(), user.Email())
+	saved.SetRole(user.Role())
+	saved.SetPasswordHash(user.PasswordHash())
+	r.users[id.Value()] = *saved
+	return saved, nil
+}
+
+// Update replaces the stored user with the same ID. Unknown users are
+// ignored, as with an UPDATE that matches no row.
+func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID().Value()]; !ok {
+		return nil
+	}
+	if err := r.checkUnique(user, user.ID().Value()); err != nil {
+		return err
+	}
+	r.users[user.ID().Value()] = *user
+	return nil
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id valueobject.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.users, id.Value())
+	return nil
+}
+
+// checkUnique fails when a user other than the one with ID self has the
+// username or email of user. The caller holds mu.
+func (r *UserRepository) checkUnique(user *entity.User, self int) error {
+	for id, other := range r.users {
+		if id == self {
+			continue
+		}
+		if other.Username() == user.Username() {
+			return fmt.Errorf("%w: username %q", ErrDuplicate, user.Username())
+		}
+		if other.Email() == user.Email() {
+			return fmt.Errorf("%w: email %q", ErrDuplicate, user.Email())
+		}
+	}
+	return nil
+}
+
+func (r *UserRepository) find(match func(*entity.User) bool) *entity.User {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, id := range slices.Sorted(maps.Keys(r.users)) {
+		user := r.users[id]
+		if match(&user) {
+			return &user
+		}
+	}
+	return nil
+}
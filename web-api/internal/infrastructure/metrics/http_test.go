@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestObserveRequestBoundsMethods(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewHTTPMetrics(reg)
+
+	m.ObserveRequest(http.MethodGet, "GET /posts", http.StatusOK, time.Millisecond)
+	for _, method := range []string{"FOO", "BAR", "get", strings.Repeat("X", 100)} {
+		m.ObserveRequest(method, "", http.StatusMethodNotAllowed, time.Millisecond)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]float64)
+	for _, family := range families {
+		if family.GetName() != namespace+"_http_requests_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "method" {
+					counts[label.GetValue()] += metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	if len(counts) != 2 || counts[http.MethodGet] != 1 || counts["other"] != 4 {
+		t.Errorf("requests by method = %v, want GET 1 and other 4", counts)
+	}
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ClientMetrics records calls made through an instrumented http.Client.
+// Calls are labelled with the name of the upstream, not the URL.
+type ClientMetrics struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+func NewClientMetrics(reg prometheus.Registerer) *ClientMetrics {
+	m := &ClientMetrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "upstream",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of outbound HTTP requests that received a response.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"upstream", "method", "status"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "upstream",
+			Name:      "errors_total",
+			Help:      "Outbound HTTP requests that failed or received a 5xx response.",
+		}, []string{"upstream", "method", "reason"}),
+	}
+	reg.MustRegister(m.duration, m.errors)
+	return m
+}
+
+// Instrument wraps the transport of client, so every call through it is
+// recorded under upstream. The client is modified in place.
+func (m *ClientMetrics) Instrument(client *http.Client, upstream string) *http.Client {
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	client.Transport = &transport{next: next, metrics: m, upstream: upstream}
+	return client
+}
+
+type transport struct {
+	next     http.RoundTripper
+	metrics  *ClientMetrics
+	upstream string
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.metrics.errors.WithLabelValues(t.upstream, req.Method, "transport").Inc()
+		return nil, err
+	}
+
+	t.metrics.duration.WithLabelValues(t.upstream, req.Method, strconv.Itoa(resp.StatusCode)).Observe(time.Since(start).Seconds())
+	if resp.StatusCode >= http.StatusInternalServerError {
+		t.metrics.errors.WithLabelValues(t.upstream, req.Method, "status").Inc()
+	}
+	return resp, nil
+}
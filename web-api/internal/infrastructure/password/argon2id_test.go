@@ -0,0 +1,64 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testParams = Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestVerify(t *testing.T) {
+	h := NewArgon2idHasher(testParams)
+	hash, err := h.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if match, needsRehash, err := h.Verify(hash, "correct horse"); !match || needsRehash || err != nil {
+		t.Errorf("Verify(correct) = %v, %v, %v, want true, false, nil", match, needsRehash, err)
+	}
+	if match, _, err := h.Verify(hash, "wrong"); match || err != nil {
+		t.Errorf("Verify(wrong) = %v, %v, want false, nil", match, err)
+	}
+
+	stronger := testParams
+	stronger.Iterations = 2
+	if match, needsRehash, _ := NewArgon2idHasher(stronger).Verify(hash, "correct horse"); !match || !needsRehash {
+		t.Errorf("Verify with new params = %v, %v, want true, true", match, needsRehash)
+	}
+}
+
+func TestVerifyRejectsInvalidHashes(t *testing.T) {
+	h := NewArgon2idHasher(testParams)
+	hash, err := h.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(hash, "$")
+	with := func(i int, value string) string {
+		p := append([]string(nil), parts...)
+		p[i] = value
+		return strings.Join(p, "$")
+	}
+
+	tests := map[string]string{
+		"empty":           "",
+		"other algorithm": with(1, "argon2i"),
+		"other version":   with(2, "v=16"),
+		"zero memory":     with(3, "m=0,t=1,p=1"),
+		"zero iterations": with(3, "m=64,t=0,p=1"),
+		"zero threads":    with(3, "m=64,t=1,p=0"),
+		"negative":        with(3, "m=64,t=-1,p=1"),
+		"bad salt":        with(4, "!"),
+		"empty key":       with(5, ""),
+	}
+	for name, hash := range tests {
+		t.Run(name, func(t *testing.T) {
+			match, _, err := h.Verify(hash, "correct horse")
+			if match || !errors.Is(err, ErrInvalidHash) {
+				t.Errorf("Verify(%q) = %v, %v, want ErrInvalidHash", hash, match, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// certificate serves a key pair from disk and reloads it when either file
+// changes. Changes are noticed on the first handshake after the reload
+// interval, so no background goroutine is needed. A pair that fails to load
+// is logged and the previous one kept, which covers the window in which a
+// renewal has replaced only one of the files.
+type certificate struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+func newCertificate(certFile, keyFile string, interval time.Duration) (*certificate, error) {
+	c := &certificate{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+	}
+	modTime, err := c.modTimes()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.load(modTime); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *certificate) get(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now := time.Now(); now.Sub(c.checked) >= c.interval {
+		c.checked = now
+		modTime, err := c.modTimes()
+		if err != nil {
+			log.Println("Failed to check TLS certificate, keeping current one:", err)
+		} else if !modTime.Equal(c.modTime) {
+			if err := c.load(modTime); err != nil {
+				log.Println("Failed to reload TLS certificate, keeping current one:", err)
+			} else {
+				log.Println("TLS certificate reloaded")
+			}
+		}
+	}
+	return c.cert, nil
+}
+
+func (c *certificate) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	c.cert = &cert
+	c.modTime = modTime
+	c.checked = time.Now()
+	return nil
+}
+
+// modTimes returns the later modification time of the two files.
+func (c *certificate) modTimes() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{c.certFile, c.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -1,29 +1,110 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
-	"log"
+	"net"
 	"net/http"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
+// Options configure the http.Server. Zero timeouts disable the respective
+// limit, so callers should always set them.
+type Options struct {
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+
+	// TLSCertFile and TLSKeyFile enable TLS. The files are checked for
+	// changes every TLSReloadInterval and reloaded without a restart.
+	TLSCertFile       string
+	TLSKeyFile        string
+	TLSReloadInterval time.Duration
+
+	// HTTP2 enables HTTP/2 over TLS. H2C enables HTTP/2 over cleartext,
+	// for deployments behind a proxy that speaks it; it is ignored with
+	// TLS.
+	HTTP2 bool
+	H2C   bool
+}
+
 type Server struct {
-	port int
+	httpServer *http.Server
+	tls        bool
 }
 
-func NewServer(port int) *Server {
-	return &Server{
-		port: port,
+// NewServer creates a server listening on addr, e.g. ":8080" for the public
+// listener or "127.0.0.1:9090" for the admin listener.
+func NewServer(addr string, handler http.Handler, opts Options) (*Server, error) {
+	useTLS := opts.TLSCertFile != "" || opts.TLSKeyFile != ""
+	if !useTLS && opts.H2C {
+		handler = h2c.NewHandler(handler, &http2.Server{IdleTimeout: opts.IdleTimeout})
+	}
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: opts.ReadHeaderTimeout,
+		ReadTimeout:       opts.ReadTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+		MaxHeaderBytes:    opts.MaxHeaderBytes,
 	}
-}
 
-func (s *Server) Start(handler http.Handler) error {
-	addr := fmt.Sprintf(":%d", s.port)
-	fmt.Printf("Server starting on %s...\n", addr)
-	return http.ListenAndServe(addr, handler)
+	if useTLS {
+		cert, err := newCertificate(opts.TLSCertFile, opts.TLSKeyFile, opts.TLSReloadInterval)
+		if err != nil {
+			return nil, err
+		}
+		httpServer.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: cert.get,
+		}
+		if opts.HTTP2 {
+			if err := http2.ConfigureServer(httpServer, &http2.Server{IdleTimeout: opts.IdleTimeout}); err != nil {
+				return nil, err
+			}
+		} else {
+			// A non-nil map keeps net/http from enabling HTTP/2.
+			httpServer.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+		}
+	}
+
+	return &Server{
+		httpServer: httpServer,
+		tls:        useTLS,
+	}, nil
 }
 
-func (s *Server) Run(handler http.Handler) {
-	if err := s.Start(handler); err != nil {
-		log.Fatal(err)
+// Start serves until Shutdown is called, after which it returns nil.
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+
+	if s.tls {
+		fmt.Printf("Server starting on %s (TLS)...\n", s.httpServer.Addr)
+		err = s.httpServer.ServeTLS(ln, "", "")
+	} else {
+		fmt.Printf("Server starting on %s...\n", s.httpServer.Addr)
+		err = s.httpServer.Serve(ln)
 	}
-}
\ No newline at end of file
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests
+// until ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"errors"
+	"time"
+)
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// ExpiresInDays is optional; keys without it never expire.
+	ExpiresInDays int `json:"expires_in_days,omitempty"`
+}
+
+func (d *CreateAPIKeyRequest) Validate() error {
+	if d.Name == "" || len(d.Scopes) == 0 {
+		return errors.New("name and scopes are required")
+	}
+	if d.ExpiresInDays < 0 {
+		return errors.New("expires_in_days must not be negative")
+	}
+	return nil
+}
+
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	// Key is the plaintext key, only returned when it is created.
+	Key string `json:"key,omitempty"`
+}
+
+type APIKeysResponse []APIKeyResponse
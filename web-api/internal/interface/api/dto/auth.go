@@ -0,0 +1,49 @@
+package dto
+
+import "errors"
+
+type RegisterRequest struct {
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (d *RegisterRequest) Validate() error {
+	if d.Name == "" || d.Username == "" || d.Email == "" || d.Password == "" {
+		return errors.New("name, username, email and password are required")
+	}
+	return nil
+}
+
+type LoginRequest struct {
+	// Login is either the username or the email address.
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+func (d *LoginRequest) Validate() error {
+	if d.Login == "" || d.Password == "" {
+		return errors.New("login and password are required")
+	}
+	return nil
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (d *RefreshTokenRequest) Validate() error {
+	if d.RefreshToken == "" {
+		return errors.New("refresh_token is required")
+	}
+	return nil
+}
+
+type TokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int    `json:"expires_in"`
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresIn int    `json:"refresh_expires_in"`
+}
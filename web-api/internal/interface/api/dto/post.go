@@ -1,5 +1,7 @@
 package dto
 
+import "errors"
+
 type PostResponse struct {
 	UserID int    `json:"userId"`
 	ID     int    `json:"id"`
@@ -7,4 +9,16 @@ type PostResponse struct {
 	Body   string `json:"body"`
 }
 
-type PostsResponse []PostResponse
\ No newline at end of file
+type PostsResponse []PostResponse
+
+type PostRequest struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+func (d *PostRequest) Validate() error {
+	if d.Title == "" {
+		return errors.New("title is required")
+	}
+	return nil
+}
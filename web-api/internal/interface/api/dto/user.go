@@ -1,10 +1,24 @@
 package dto
 
+import "errors"
+
 type UserResponse struct {
 	ID       int    `json:"id"`
 	Name     string `json:"name"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	Role     string `json:"role,omitempty"`
 }
 
-type UsersResponse []UserResponse
\ No newline at end of file
+type UsersResponse []UserResponse
+
+type ChangeRoleRequest struct {
+	Role string `json:"role"`
+}
+
+func (d *ChangeRoleRequest) Validate() error {
+	if d.Role == "" {
+		return errors.New("role is required")
+	}
+	return nil
+}
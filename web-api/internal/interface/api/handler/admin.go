@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/dto"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/problem"
+	userUseCase "github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/user"
+)
+
+type AdminHandler struct {
+	userService *userUseCase.Service
+}
+
+func NewAdminHandler(userService *userUseCase.Service) *AdminHandler {
+	return &AdminHandler{
+		userService: userService,
+	}
+}
+
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.userService.ListUsers(r.Context())
+	if err != nil {
+		if writePolicyError(w, err) {
+			return
+		}
+		problem.Write(w, http.StatusInternalServerError, "Failed to fetch users")
+		return
+	}
+
+	response := make(dto.UsersResponse, len(users))
+	for i, user := range users {
+		response[i] = toAdminUserResponse(user)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *AdminHandler) ChangeUserRole(w http.ResponseWriter, r *http.Request) {
+	var req dto.ChangeRoleRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		problem.Write(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.userService.ChangeRole(r.Context(), r.PathValue("id"), req.Role)
+	if err != nil {
+		if writePolicyError(w, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, userUseCase.ErrInvalidRole), errors.Is(err, userUseCase.ErrInvalidID):
+			problem.Write(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, userUseCase.ErrUserNotFound):
+			problem.Write(w, http.StatusNotFound, "User not found")
+		case errors.Is(err, userUseCase.ErrUserReadOnly):
+			problem.Write(w, http.StatusConflict, "User is read-only")
+		default:
+			problem.Write(w, http.StatusInternalServerError, "Failed to update user")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toAdminUserResponse(user))
+}
+
+func toAdminUserResponse(user *entity.User) dto.UserResponse {
+	response := toUserResponse(user)
+	response.Role = user.Role().String()
+	return response
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/jwt"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/memory"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/middleware"
+	userUseCase "github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/user"
+)
+
+func TestAdminRejectsExternalTokens(t *testing.T) {
+	ctx := context.Background()
+
+	// The service signs its own tokens and trusts the key of another
+	// issuer.
+	pub, external, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk, err := jwt.NewJWK(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(jwt.JWKS{Keys: []jwt.JWK{jwk}})
+	jwksPath := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(jwksPath, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if _, err := jwt.WriteSigningKey(dir); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := jwt.NewKeySet(jwksPath, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := memory.NewUserRepository()
+	email, _ := valueobject.NewEmail("admin@example.com")
+	user := entity.NewUser(valueobject.UserID{}, "Admin", "admin", email)
+	user.SetRole(valueobject.RoleAdmin)
+	admin, err := users.Save(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ownToken, _, err := jwt.NewIssuer(keys, "api", "api", time.Minute).IssueAccessToken(admin.ID(), valueobject.RoleAdmin, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	forged := gojwt.NewWithClaims(gojwt.SigningMethodEdDSA, jwt.Claims{
+		RegisteredClaims: gojwt.RegisteredClaims{
+			Issuer:    "api",
+			Subject:   admin.ID().String(),
+			Audience:  gojwt.ClaimStrings{"api"},
+			IssuedAt:  gojwt.NewNumericDate(now),
+			ExpiresAt: gojwt.NewNumericDate(now.Add(time.Minute)),
+		},
+		Role: valueobject.RoleAdmin.String(),
+	})
+	forged.Header["kid"] = jwk.Kid
+	externalToken, err := forged.SignedString(external)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := middleware.Authenticate(map[string]middleware.Authenticator{
+		"Bearer": jwt.NewVerifier(keys, users, "api", "api", 0),
+	})(http.HandlerFunc(NewAdminHandler(userUseCase.NewService(users)).ListUsers))
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"own token", ownToken, http.StatusOK},
+		// The external token acts as no local user, whatever it claims.
+		{"external token claiming admin", externalToken, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
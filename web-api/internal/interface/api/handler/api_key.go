@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/dto"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/problem"
+	apiKeyUseCase "github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/apikey"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *apiKeyUseCase.Service
+}
+
+func NewAPIKeyHandler(apiKeyService *apiKeyUseCase.Service) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateAPIKeyRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		problem.Write(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ttl := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	key, plaintext, err := h.apiKeyService.Create(r.Context(), req.Name, req.Scopes, ttl)
+	if err != nil {
+		if writePolicyError(w, err) {
+			return
+		}
+		if errors.Is(err, apiKeyUseCase.ErrInvalidInput) {
+			problem.Write(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		problem.Write(w, http.StatusInternalServerError, "Failed to create API key")
+		return
+	}
+
+	response := toAPIKeyResponse(key)
+	response.Key = plaintext
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeyService.List(r.Context())
+	if err != nil {
+		if writePolicyError(w, err) {
+			return
+		}
+		problem.Write(w, http.StatusInternalServerError, "Failed to fetch API keys")
+		return
+	}
+
+	response := make(dto.APIKeysResponse, len(keys))
+	for i, key := range keys {
+		response[i] = toAPIKeyResponse(key)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	if err := h.apiKeyService.Revoke(r.Context(), r.PathValue("id")); err != nil {
+		if writePolicyError(w, err) {
+			return
+		}
+		if errors.Is(err, apiKeyUseCase.ErrAPIKeyNotFound) {
+			problem.Write(w, http.StatusNotFound, "API key not found")
+			return
+		}
+		problem.Write(w, http.StatusInternalServerError, "Failed to revoke API key")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toAPIKeyResponse(key *entity.APIKey) dto.APIKeyResponse {
+	response := dto.APIKeyResponse{
+		ID:        key.ID(),
+		Name:      key.Name(),
+		Scopes:    key.Scopes(),
+		CreatedAt: key.CreatedAt(),
+	}
+	if !key.ExpiresAt().IsZero() {
+		expiresAt := key.ExpiresAt()
+		response.ExpiresAt = &expiresAt
+	}
+	if !key.LastUsedAt().IsZero() {
+		lastUsedAt := key.LastUsedAt()
+		response.LastUsedAt = &lastUsedAt
+	}
+	return response
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/auth"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/dto"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/problem"
+	accountUseCase "github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/account"
+)
+
+type AuthHandler struct {
+	accountService *accountUseCase.Service
+}
+
+func NewAuthHandler(accountService *accountUseCase.Service) *AuthHandler {
+	return &AuthHandler{
+		accountService: accountService,
+	}
+}
+
+func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var req dto.RegisterRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		problem.Write(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.accountService.Register(r.Context(), req.Name, req.Username, req.Email, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, accountUseCase.ErrInvalidInput):
+			problem.Write(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, accountUseCase.ErrUsernameTaken), errors.Is(err, accountUseCase.ErrEmailTaken):
+			problem.Write(w, http.StatusConflict, err.Error())
+		default:
+			problem.Write(w, http.StatusInternalServerError, "Failed to register user")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toUserResponse(user))
+}
+
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req dto.LoginRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		problem.Write(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tokens, err := h.accountService.Login(r.Context(), req.Login, req.Password)
+	if err != nil {
+		if errors.Is(err, accountUseCase.ErrInvalidCredentials) {
+			problem.Write(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		problem.Write(w, http.StatusInternalServerError, "Failed to login")
+		return
+	}
+
+	writeTokens(w, tokens)
+}
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshTokenRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		problem.Write(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tokens, err := h.accountService.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, accountUseCase.ErrInvalidRefreshToken) || errors.Is(err, accountUseCase.ErrRefreshTokenReused) {
+			problem.Write(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		problem.Write(w, http.StatusInternalServerError, "Failed to refresh token")
+		return
+	}
+
+	writeTokens(w, tokens)
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshTokenRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		problem.Write(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.accountService.Logout(r.Context(), req.RefreshToken); err != nil {
+		if errors.Is(err, accountUseCase.ErrInvalidRefreshToken) {
+			problem.Write(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		problem.Write(w, http.StatusInternalServerError, "Failed to logout")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Revoke signs the authenticated user out of all sessions.
+func (h *AuthHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	principal := auth.PrincipalFromContext(r.Context())
+	if principal == nil || principal.UserID().Value() == 0 {
+		problem.Write(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	if err := h.accountService.RevokeAll(r.Context(), principal.UserID()); err != nil {
+		problem.Write(w, http.StatusInternalServerError, "Failed to revoke tokens")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeTokens(w http.ResponseWriter, tokens *accountUseCase.TokenPair) {
+	now := time.Now()
+	response := dto.TokenResponse{
+		AccessToken:      tokens.AccessToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int(tokens.AccessExpiresAt.Sub(now).Seconds()),
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresIn: int(tokens.RefreshExpiresAt.Sub(now).Seconds()),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/auth"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/memory"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/problem"
+	postUseCase "github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/post"
+	userUseCase "github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/user"
+)
+
+func TestHandlersWriteProblems(t *testing.T) {
+	users := memory.NewUserRepository()
+	email, _ := valueobject.NewEmail("admin@example.com")
+	admin, err := users.Save(context.Background(), entity.NewUser(valueobject.UserID{}, "Admin", "admin", email))
+	if err != nil {
+		t.Fatal(err)
+	}
+	principal := auth.NewPrincipal(admin.ID().String(), admin.ID(), valueobject.RoleAdmin, auth.MethodJWT,
+		[]string{auth.ScopePostsRead, auth.ScopePostsWrite, auth.ScopeUsersRead})
+
+	posts := NewPostHandler(postUseCase.NewService(memory.NewPostRepository(users)))
+	userHandler := NewUserHandler(userUseCase.NewService(users))
+	adminHandler := NewAdminHandler(userUseCase.NewService(users))
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		id      string
+		body    string
+		want    int
+	}{
+		{"get post malformed", posts.GetPost, http.MethodGet, "abc", "", http.StatusBadRequest},
+		{"get post zero", posts.GetPost, http.MethodGet, "0", "", http.StatusBadRequest},
+		{"get post missing", posts.GetPost, http.MethodGet, "42", "", http.StatusNotFound},
+		{"update post malformed", posts.UpdatePost, http.MethodPut, "abc", `{"title":"t","body":"b"}`, http.StatusBadRequest},
+		{"update post missing", posts.UpdatePost, http.MethodPut, "42", `{"title":"t","body":"b"}`, http.StatusNotFound},
+		{"delete post malformed", posts.DeletePost, http.MethodDelete, "abc", "", http.StatusBadRequest},
+		{"delete post missing", posts.DeletePost, http.MethodDelete, "42", "", http.StatusNotFound},
+		{"get user malformed", userHandler.GetUser, http.MethodGet, "abc", "", http.StatusBadRequest},
+		{"get user missing", userHandler.GetUser, http.MethodGet, "42", "", http.StatusNotFound},
+		{"change role malformed", adminHandler.ChangeUserRole, http.MethodPut, "abc", `{"role":"moderator"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.SetPathValue("id", tt.id)
+			req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+			rec := httptest.NewRecorder()
+
+			tt.handler(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("Content-Type = %q, want application/problem+json", ct)
+			}
+			var details problem.Details
+			if err := json.NewDecoder(rec.Body).Decode(&details); err != nil || details.Status != tt.want {
+				t.Errorf("body = %+v, %v", details, err)
+			}
+		})
+	}
+}
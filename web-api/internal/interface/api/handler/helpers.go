@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/problem"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/policy"
+)
+
+const maxRequestBodySize = 1 << 20
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid request body")
+		return false
+	}
+	return true
+}
+
+// writePolicyError writes the response for authorization failures and
+// reports whether err was one.
+func writePolicyError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, policy.ErrUnauthenticated):
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		problem.Write(w, http.StatusUnauthorized, "Authentication required")
+	case errors.Is(err, policy.ErrForbidden):
+		problem.Write(w, http.StatusForbidden, "You are not allowed to perform this action")
+	default:
+		return false
+	}
+	return true
+}
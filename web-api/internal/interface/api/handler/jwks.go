@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/problem"
+)
+
+type JWKSProvider interface {
+	PublicJWKS() ([]byte, error)
+}
+
+type JWKSHandler struct {
+	provider JWKSProvider
+}
+
+func NewJWKSHandler(provider JWKSProvider) *JWKSHandler {
+	return &JWKSHandler{
+		provider: provider,
+	}
+}
+
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	body, err := h.provider.PublicJWKS()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, "Failed to load keys")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Write(body)
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/problem"
+	accountUseCase "github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/account"
+)
+
+const (
+	ssoCookieName = "oidc_session"
+	ssoCookiePath = "/auth/oidc"
+	ssoSessionTTL = 10 * time.Minute
+)
+
+// OIDCHandler serves the login redirect and the callback of the
+// authorization code flow. The login state travels in an HMAC signed
+// cookie, so any replica can complete a login another one started.
+type OIDCHandler struct {
+	ssoService   *accountUseCase.SSOService
+	cookieSecret []byte
+	// secureCookie marks the cookie Secure. It is set from the callback
+	// URL rather than the request, which lacks TLS behind a proxy that
+	// terminates it.
+	secureCookie bool
+}
+
+func NewOIDCHandler(ssoService *accountUseCase.SSOService, cookieSecret []byte, secureCookie bool) *OIDCHandler {
+	return &OIDCHandler{
+		ssoService:   ssoService,
+		cookieSecret: cookieSecret,
+		secureCookie: secureCookie,
+	}
+}
+
+type ssoCookie struct {
+	accountUseCase.SSOSession
+	ExpiresAt int64 `json:"exp"`
+}
+
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	session, authURL, err := h.ssoService.Begin(r.Context())
+	if err != nil {
+		problem.Write(w, http.StatusBadGateway, "Identity provider is unavailable")
+		return
+	}
+
+	value, err := h.encodeSession(session)
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, "Failed to start login")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     ssoCookieName,
+		Value:    value,
+		Path:     ssoCookiePath,
+		MaxAge:   int(ssoSessionTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   h.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	// The session is single use whatever the outcome.
+	http.SetCookie(w, &http.Cookie{
+		Name:     ssoCookieName,
+		Path:     ssoCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	q := r.URL.Query()
+	if errCode := q.Get("error"); errCode != "" {
+		problem.Write(w, http.StatusUnauthorized, "Identity provider returned "+errCode)
+		return
+	}
+
+	var session *accountUseCase.SSOSession
+	if cookie, err := r.Cookie(ssoCookieName); err == nil {
+		session = h.decodeSession(cookie.Value)
+	}
+
+	tokens, err := h.ssoService.Complete(r.Context(), session, q.Get("state"), q.Get("code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, accountUseCase.ErrInvalidSSOState):
+			problem.Write(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, accountUseCase.ErrSSOFailed), errors.Is(err, accountUseCase.ErrEmailNotVerified):
+			problem.Write(w, http.StatusUnauthorized, err.Error())
+		default:
+			problem.Write(w, http.StatusInternalServerError, "Failed to login")
+		}
+		return
+	}
+
+	writeTokens(w, tokens)
+}
+
+func (h *OIDCHandler) encodeSession(session *accountUseCase.SSOSession) (string, error) {
+	payload, err := json.Marshal(ssoCookie{
+		SSOSession: *session,
+		ExpiresAt:  time.Now().Add(ssoSessionTTL).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + h.sign(encoded), nil
+}
+
+func (h *OIDCHandler) decodeSession(value string) *accountUseCase.SSOSession {
+	encoded, signature, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(h.sign(encoded))) {
+		return nil
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil
+	}
+	var cookie ssoCookie
+	if err := json.Unmarshal(payload, &cookie); err != nil {
+		return nil
+	}
+	if time.Now().Unix() > cookie.ExpiresAt {
+		return nil
+	}
+	return &cookie.SSOSession
+}
+
+func (h *OIDCHandler) sign(value string) string {
+	mac := hmac.New(sha256.New, h.cookieSecret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
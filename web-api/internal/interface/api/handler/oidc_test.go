@@ -0,0 +1,303 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/jwt"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/memory"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/oidc"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/oidc/oidctest"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/password"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/dto"
+	accountUseCase "github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/account"
+)
+
+const callbackURL = "http://api.test/auth/oidc/callback"
+
+type oidcEnv struct {
+	provider   *oidctest.Provider
+	handler    *OIDCHandler
+	verifier   *jwt.Verifier
+	users      *memory.UserRepository
+	identities *memory.IdentityRepository
+}
+
+func newOIDCEnv(t *testing.T, user oidctest.User) *oidcEnv {
+	t.Helper()
+
+	provider := oidctest.NewProvider(user)
+	t.Cleanup(provider.Close)
+
+	dir := t.TempDir()
+	if _, err := jwt.WriteSigningKey(dir); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := jwt.NewKeySet("", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := memory.NewUserRepository()
+	identities := memory.NewIdentityRepository()
+	hasher := password.NewArgon2idHasher(password.Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	accounts := accountUseCase.NewService(users, memory.NewRefreshTokenRepository(), hasher, jwt.NewIssuer(keys, "api", "api", time.Minute), time.Hour)
+	client := oidc.NewClient(oidc.Config{
+		IssuerURL:    provider.Issuer(),
+		ClientID:     oidctest.ClientID,
+		ClientSecret: oidctest.ClientSecret,
+		RedirectURL:  callbackURL,
+	}, provider.Client())
+
+	return &oidcEnv{
+		provider:   provider,
+		handler:    NewOIDCHandler(accountUseCase.NewSSOService(accounts, identities, client), []byte("secret"), false),
+		verifier:   jwt.NewVerifier(keys, users, "api", "api", 0),
+		users:      users,
+		identities: identities,
+	}
+}
+
+// login runs the login endpoint and the provider's authorization, and
+// returns the session cookie and the callback URL the provider redirected
+// to.
+func (e *oidcEnv) login(t *testing.T) (*http.Cookie, *url.URL) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	e.handler.Login(rec, httptest.NewRequest(http.MethodGet, "/auth/oidc/login", nil))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("login status = %d, want %d", rec.Code, http.StatusFound)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != ssoCookieName {
+		t.Fatalf("login cookies = %v", cookies)
+	}
+
+	client := *e.provider.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Get(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	callback, err := resp.Location()
+	if err != nil {
+		t.Fatalf("authorize did not redirect: %v", err)
+	}
+	return cookies[0], callback
+}
+
+func (e *oidcEnv) callback(cookie *http.Cookie, callback *url.URL) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, callback.String(), nil)
+	req.AddCookie(cookie)
+	rec := httptest.NewRecorder()
+	e.handler.Callback(rec, req)
+	return rec
+}
+
+// userID verifies the access token of a successful callback and returns
+// its user.
+func (e *oidcEnv) userID(t *testing.T, rec *httptest.ResponseRecorder) valueobject.UserID {
+	t.Helper()
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("callback status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	var tokens dto.TokenResponse
+	if err := json.NewDecoder(rec.Body).Decode(&tokens); err != nil {
+		t.Fatal(err)
+	}
+	if tokens.RefreshToken == "" {
+		t.Error("callback issued no refresh token")
+	}
+	principal, err := e.verifier.Authenticate(context.Background(), tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("access token: %v", err)
+	}
+	return principal.UserID()
+}
+
+// withSession re-signs cookie with the session changed by change, as a
+// client could by replaying parts of another login.
+func (e *oidcEnv) withSession(t *testing.T, cookie *http.Cookie, change func(*accountUseCase.SSOSession)) *http.Cookie {
+	t.Helper()
+
+	session := e.handler.decodeSession(cookie.Value)
+	if session == nil {
+		t.Fatal("login cookie does not decode")
+	}
+	change(session)
+	value, err := e.handler.encodeSession(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Cookie{Name: cookie.Name, Value: value}
+}
+
+var leanne = oidctest.User{
+	Subject:           "sub-1",
+	Email:             "leanne@example.com",
+	EmailVerified:     true,
+	Name:              "Leanne Graham",
+	PreferredUsername: "leanne",
+}
+
+func TestOIDCLoginCreatesUser(t *testing.T) {
+	e := newOIDCEnv(t, leanne)
+
+	id := e.userID(t, e.callback(e.login(t)))
+
+	user, err := e.users.FindByID(context.Background(), id)
+	if err != nil || user == nil {
+		t.Fatalf("FindByID(%v) = %v, %v", id, user, err)
+	}
+	if user.Username() != "leanne" || user.Email().String() != leanne.Email || user.Name() != leanne.Name {
+		t.Errorf("user = %s %s %q", user.Username(), user.Email(), user.Name())
+	}
+
+	// The identity is linked, so the next login finds the same user.
+	if again := e.userID(t, e.callback(e.login(t))); again != id {
+		t.Errorf("second login user = %v, want %v", again, id)
+	}
+}
+
+func TestOIDCLoginLinksExistingEmail(t *testing.T) {
+	e := newOIDCEnv(t, leanne)
+	ctx := context.Background()
+
+	email, _ := valueobject.NewEmail(leanne.Email)
+	existing, err := e.users.Save(ctx, entity.NewUser(valueobject.UserID{}, "Leanne", "lgraham", email))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id := e.userID(t, e.callback(e.login(t))); id != existing.ID() {
+		t.Fatalf("login user = %v, want existing %v", id, existing.ID())
+	}
+	identity, err := e.identities.FindBySubject(ctx, e.provider.Issuer(), leanne.Subject)
+	if err != nil || identity == nil || identity.UserID() != existing.ID() {
+		t.Fatalf("identity = %v, %v, want linked to %v", identity, err, existing.ID())
+	}
+	users, _ := e.users.FindAll(ctx)
+	if len(users) != 1 {
+		t.Errorf("users = %d, want 1", len(users))
+	}
+
+	// Once linked, the identity wins over a changed email.
+	changed := leanne
+	changed.Email = "leanne@example.org"
+	e.provider.SetUser(changed)
+	if id := e.userID(t, e.callback(e.login(t))); id != existing.ID() {
+		t.Errorf("login with changed email user = %v, want %v", id, existing.ID())
+	}
+}
+
+func TestOIDCLoginRejectsUnverifiedEmail(t *testing.T) {
+	unverified := leanne
+	unverified.EmailVerified = false
+	e := newOIDCEnv(t, unverified)
+
+	email, _ := valueobject.NewEmail(leanne.Email)
+	if _, err := e.users.Save(context.Background(), entity.NewUser(valueobject.UserID{}, "Leanne", "lgraham", email)); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec := e.callback(e.login(t)); rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestOIDCCallbackRejectsMismatches(t *testing.T) {
+	tests := []struct {
+		name string
+		// tamper changes a completed authorization before the callback.
+		tamper func(t *testing.T, e *oidcEnv, cookie *http.Cookie, callback *url.URL) (*http.Cookie, *url.URL)
+		want   int
+	}{
+		{
+			name: "state",
+			tamper: func(t *testing.T, e *oidcEnv, cookie *http.Cookie, callback *url.URL) (*http.Cookie, *url.URL) {
+				q := callback.Query()
+				q.Set("state", "forged")
+				callback.RawQuery = q.Encode()
+				return cookie, callback
+			},
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "missing session",
+			tamper: func(t *testing.T, e *oidcEnv, cookie *http.Cookie, callback *url.URL) (*http.Cookie, *url.URL) {
+				return &http.Cookie{Name: ssoCookieName, Value: "invalid"}, callback
+			},
+			want: http.StatusBadRequest,
+		},
+		{
+			// The code of one login is redeemed with the session of
+			// another, so its PKCE verifier does not match.
+			name: "PKCE verifier",
+			tamper: func(t *testing.T, e *oidcEnv, cookie *http.Cookie, callback *url.URL) (*http.Cookie, *url.URL) {
+				other, otherCallback := e.login(t)
+				q := callback.Query()
+				q.Set("state", otherCallback.Query().Get("state"))
+				callback.RawQuery = q.Encode()
+				return other, callback
+			},
+			want: http.StatusUnauthorized,
+		},
+		{
+			name: "nonce",
+			tamper: func(t *testing.T, e *oidcEnv, cookie *http.Cookie, callback *url.URL) (*http.Cookie, *url.URL) {
+				return e.withSession(t, cookie, func(s *accountUseCase.SSOSession) { s.Nonce = "forged" }), callback
+			},
+			want: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newOIDCEnv(t, leanne)
+
+			cookie, callback := e.login(t)
+			cookie, callback = tt.tamper(t, e, cookie, callback)
+			rec := e.callback(cookie, callback)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if users, _ := e.users.FindAll(context.Background()); len(users) != 0 {
+				t.Errorf("users = %d, want none", len(users))
+			}
+		})
+	}
+}
+
+func TestOIDCCookieSecure(t *testing.T) {
+	e := newOIDCEnv(t, leanne)
+
+	for _, secure := range []bool{false, true} {
+		h := NewOIDCHandler(e.handler.ssoService, []byte("secret"), secure)
+
+		// Behind a proxy that terminates TLS the request has none.
+		rec := httptest.NewRecorder()
+		h.Login(rec, httptest.NewRequest(http.MethodGet, "/auth/oidc/login", nil))
+		login := rec.Result().Cookies()
+
+		rec = httptest.NewRecorder()
+		h.Callback(rec, httptest.NewRequest(http.MethodGet, "/auth/oidc/callback", nil))
+		cleared := rec.Result().Cookies()
+
+		if len(login) != 1 || len(cleared) != 1 {
+			t.Fatalf("cookies = %v and %v", login, cleared)
+		}
+		if login[0].Secure != secure || cleared[0].Secure != secure {
+			t.Errorf("Secure = %v and %v, want %v", login[0].Secure, cleared[0].Secure, secure)
+		}
+	}
+}
@@ -2,9 +2,12 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/dto"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/problem"
 	postUseCase "github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/post"
 )
 
@@ -19,25 +22,15 @@ func NewPostHandler(postService *postUseCase.Service) *PostHandler {
 }
 
 func (h *PostHandler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	posts, err := h.postService.GetAllPosts(r.Context())
 	if err != nil {
-		http.Error(w, "Failed to fetch posts", http.StatusInternalServerError)
+		h.writeError(w, err, "Failed to fetch posts")
 		return
 	}
 
 	response := make(dto.PostsResponse, len(posts))
 	for i, post := range posts {
-		response[i] = dto.PostResponse{
-			ID:     post.ID().Value(),
-			UserID: post.UserID().Value(),
-			Title:  post.Title(),
-			Body:   post.Body(),
-		}
+		response[i] = toPostResponse(post)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -45,34 +38,87 @@ func (h *PostHandler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	post, err := h.postService.GetPostByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, err, "Failed to fetch post")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toPostResponse(post))
+}
+
+func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
+	var req dto.PostRequest
+	if !decodeRequest(w, r, &req) {
 		return
 	}
+	if err := req.Validate(); err != nil {
+		problem.Write(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	post, err := h.postService.CreatePost(r.Context(), req.Title, req.Body)
+	if err != nil {
+		h.writeError(w, err, "Failed to create post")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toPostResponse(post))
+}
 
-	id := r.URL.Path[len("/posts/"):]
-	if id == "" {
-		http.Error(w, "Post ID required", http.StatusBadRequest)
+func (h *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
+	var req dto.PostRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		problem.Write(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	post, err := h.postService.GetPostByID(r.Context(), id)
+	post, err := h.postService.UpdatePost(r.Context(), r.PathValue("id"), req.Title, req.Body)
 	if err != nil {
-		if err.Error() == "post not found" {
-			http.Error(w, "Post not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, "Failed to fetch post", http.StatusInternalServerError)
+		h.writeError(w, err, "Failed to update post")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toPostResponse(post))
+}
+
+func (h *PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
+	if err := h.postService.DeletePost(r.Context(), r.PathValue("id")); err != nil {
+		h.writeError(w, err, "Failed to delete post")
 		return
 	}
 
-	response := dto.PostResponse{
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PostHandler) writeError(w http.ResponseWriter, err error, fallback string) {
+	if writePolicyError(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, postUseCase.ErrPostNotFound):
+		problem.Write(w, http.StatusNotFound, "Post not found")
+	case errors.Is(err, postUseCase.ErrInvalidInput), errors.Is(err, postUseCase.ErrInvalidID):
+		problem.Write(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, postUseCase.ErrPostReadOnly):
+		problem.Write(w, http.StatusConflict, "Post is read-only")
+	default:
+		problem.Write(w, http.StatusInternalServerError, fallback)
+	}
+}
+
+func toPostResponse(post *entity.Post) dto.PostResponse {
+	return dto.PostResponse{
 		ID:     post.ID().Value(),
 		UserID: post.UserID().Value(),
 		Title:  post.Title(),
 		Body:   post.Body(),
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
-}
\ No newline at end of file
+}
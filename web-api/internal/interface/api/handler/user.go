@@ -2,9 +2,12 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/dto"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/problem"
 	userUseCase "github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/user"
 )
 
@@ -19,25 +22,15 @@ func NewUserHandler(userService *userUseCase.Service) *UserHandler {
 }
 
 func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	users, err := h.userService.GetAllUsers(r.Context())
 	if err != nil {
-		http.Error(w, "Failed to fetch users", http.StatusInternalServerError)
+		h.writeError(w, err, "Failed to fetch users")
 		return
 	}
 
 	response := make(dto.UsersResponse, len(users))
 	for i, user := range users {
-		response[i] = dto.UserResponse{
-			ID:       user.ID().Value(),
-			Name:     user.Name(),
-			Username: user.Username(),
-			Email:    user.Email().String(),
-		}
+		response[i] = toUserResponse(user)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -45,34 +38,35 @@ func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	user, err := h.userService.GetUserByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, err, "Failed to fetch user")
 		return
 	}
 
-	id := r.URL.Path[len("/users/"):]
-	if id == "" {
-		http.Error(w, "User ID required", http.StatusBadRequest)
-		return
-	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toUserResponse(user))
+}
 
-	user, err := h.userService.GetUserByID(r.Context(), id)
-	if err != nil {
-		if err.Error() == "user not found" {
-			http.Error(w, "User not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, "Failed to fetch user", http.StatusInternalServerError)
+func (h *UserHandler) writeError(w http.ResponseWriter, err error, fallback string) {
+	if writePolicyError(w, err) {
 		return
 	}
+	switch {
+	case errors.Is(err, userUseCase.ErrUserNotFound):
+		problem.Write(w, http.StatusNotFound, "User not found")
+	case errors.Is(err, userUseCase.ErrInvalidID):
+		problem.Write(w, http.StatusBadRequest, err.Error())
+	default:
+		problem.Write(w, http.StatusInternalServerError, fallback)
+	}
+}
 
-	response := dto.UserResponse{
+func toUserResponse(user *entity.User) dto.UserResponse {
+	return dto.UserResponse{
 		ID:       user.ID().Value(),
 		Name:     user.Name(),
 		Username: user.Username(),
 		Email:    user.Email().String(),
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
-}
\ No newline at end of file
+}
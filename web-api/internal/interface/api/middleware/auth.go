@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/auth"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/problem"
+)
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, credentials string) (*auth.Principal, error)
+}
+
+// Authenticate resolves the Authorization header with the authenticator
+// registered for its scheme and stores the principal in the request
+// context. Requests without credentials pass through anonymously; invalid
+// credentials are rejected with 401.
+func Authenticate(authenticators map[string]Authenticator) func(http.Handler) http.Handler {
+	byScheme := make(map[string]Authenticator, len(authenticators))
+	for scheme, a := range authenticators {
+		byScheme[strings.ToLower(scheme)] = a
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scheme, credentials, _ := strings.Cut(header, " ")
+			authenticator, ok := byScheme[strings.ToLower(scheme)]
+			if !ok {
+				unauthorized(w, "Unsupported authorization scheme")
+				return
+			}
+
+			principal, err := authenticator.Authenticate(r.Context(), strings.TrimSpace(credentials))
+			if err != nil {
+				unauthorized(w, "Invalid credentials")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// RequireAuth rejects anonymous requests with 401.
+func RequireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth.PrincipalFromContext(r.Context()) == nil {
+			unauthorized(w, "Authentication required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func unauthorized(w http.ResponseWriter, detail string) {
+	w.Header().Set("WWW-Authenticate", `Bearer`)
+	problem.Write(w, http.StatusUnauthorized, detail)
+}
@@ -0,0 +1,284 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/auth"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/problem"
+)
+
+// Limit is a token bucket refilled at Rate tokens per second up to Burst.
+// A zero Rate disables limiting; a Burst below one is treated as one.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) normalize() Limit {
+	if l.Burst < 1 {
+		l.Burst = 1
+	}
+	return l
+}
+
+// window is the time an empty bucket takes to fill up.
+func (l Limit) window() time.Duration {
+	return seconds(float64(l.Burst) / l.Rate)
+}
+
+type bucket struct {
+	tokens   float64
+	updated  time.Time
+	lastSeen time.Time
+}
+
+// RateLimiter limits requests per client. Clients are identified by API key,
+// then by user, then by IP address. Routes with their own limit get their
+// own buckets; all other routes share the default bucket of a client.
+//
+// IPMiddleware additionally limits every IP address before authentication,
+// so that clients cannot make the server verify credentials at will.
+type RateLimiter struct {
+	policy       atomic.Pointer[ratePolicy]
+	idleTTL      time.Duration
+	routePattern func(*http.Request) string
+	exempt       map[string]bool
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// ratePolicy holds the settings that can be replaced while serving.
+type ratePolicy struct {
+	ipLimit        Limit
+	defaultLimit   Limit
+	routeLimits    map[string]Limit
+	trustedProxies []netip.Prefix
+}
+
+// NewRateLimiter creates a limiter. routeLimits are keyed by the ServeMux
+// pattern returned by routePattern, e.g. "POST /auth/login". Requests to the
+// exempt patterns, such as health checks, are never limited.
+func NewRateLimiter(ipLimit, defaultLimit Limit, routeLimits map[string]Limit, trustedProxies []netip.Prefix, idleTTL time.Duration, routePattern func(*http.Request) string, exempt ...string) *RateLimiter {
+	l := &RateLimiter{
+		idleTTL:      idleTTL,
+		routePattern: routePattern,
+		exempt:       make(map[string]bool, len(exempt)),
+		buckets:      make(map[string]*bucket),
+	}
+	for _, pattern := range exempt {
+		l.exempt[pattern] = true
+	}
+	l.SetLimits(ipLimit, defaultLimit, routeLimits, trustedProxies)
+	return l
+}
+
+// SetLimits replaces the limits and trusted proxies. Existing buckets are
+// kept, so clients do not get a fresh burst; they are refilled at the new
+// rate and capped at the new burst.
+func (l *RateLimiter) SetLimits(ipLimit, defaultLimit Limit, routeLimits map[string]Limit, trustedProxies []netip.Prefix) {
+	limits := make(map[string]Limit, len(routeLimits))
+	for pattern, limit := range routeLimits {
+		limits[pattern] = limit.normalize()
+	}
+	l.policy.Store(&ratePolicy{
+		ipLimit:        ipLimit.normalize(),
+		defaultLimit:   defaultLimit.normalize(),
+		routeLimits:    limits,
+		trustedProxies: trustedProxies,
+	})
+}
+
+// IPMiddleware limits requests per IP address. It runs before
+// authentication, and all requests of an address share one bucket.
+func (l *RateLimiter) IPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if l.exempt[l.routePattern(r)] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		policy := l.policy.Load()
+		if !l.allow(w, "addr:"+policy.clientIP(r), policy.ipLimit) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Middleware limits requests per client. It runs after authentication.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pattern := l.routePattern(r)
+		if l.exempt[pattern] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		policy := l.policy.Load()
+		limit := policy.defaultLimit
+		key := policy.clientKey(r)
+		if routeLimit, ok := policy.routeLimits[pattern]; ok && pattern != "" {
+			limit = routeLimit
+			key = pattern + "|" + key
+		}
+
+		if !l.allow(w, key, limit) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allow takes a token from the bucket key and sets the rate limit headers.
+// It writes the error response and returns false if the bucket is empty.
+func (l *RateLimiter) allow(w http.ResponseWriter, key string, limit Limit) bool {
+	if limit.Rate <= 0 {
+		return true
+	}
+
+	allowed, remaining, retryAfter, reset := l.take(key, limit, time.Now())
+
+	h := w.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+	h.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+	h.Set("RateLimit-Policy", strconv.Itoa(limit.Burst)+";w="+strconv.Itoa(ceilSeconds(limit.window())))
+
+	if !allowed {
+		h.Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+		problem.Write(w, http.StatusTooManyRequests, "Rate limit exceeded")
+		return false
+	}
+	return true
+}
+
+// take consumes a token and returns whether the request is allowed, the
+// whole tokens left, the wait until the next token and the wait until the
+// bucket is full again.
+func (l *RateLimiter) take(key string, limit Limit, now time.Time) (bool, int, time.Duration, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+
+	elapsed := now.Sub(b.updated).Seconds()
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	var retryAfter time.Duration
+	if !allowed {
+		retryAfter = seconds((1 - b.tokens) / limit.Rate)
+	}
+	reset := seconds((float64(limit.Burst) - b.tokens) / limit.Rate)
+
+	return allowed, int(b.tokens), retryAfter, reset
+}
+
+// Run evicts buckets of clients that have been idle for the idle TTL until
+// ctx is cancelled, so memory stays bounded by the number of active clients.
+func (l *RateLimiter) Run(ctx context.Context) {
+	interval := l.idleTTL / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			l.evict(now)
+		}
+	}
+}
+
+func (l *RateLimiter) evict(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) >= l.idleTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func (p *ratePolicy) clientKey(r *http.Request) string {
+	if principal := auth.PrincipalFromContext(r.Context()); principal != nil {
+		if principal.Method() == auth.MethodAPIKey {
+			return principal.Subject()
+		}
+		if principal.UserID().Value() != 0 {
+			return "user:" + principal.UserID().String()
+		}
+		return principal.Method() + ":" + principal.Subject()
+	}
+	return "ip:" + p.clientIP(r)
+}
+
+// clientIP returns the address of the client. X-Forwarded-For is only
+// honoured when the request comes from a trusted proxy, and is read from the
+// right so that clients cannot spoof entries added by our own proxies.
+func (p *ratePolicy) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !p.isTrusted(addr) {
+		return host
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		addr = hop.Unmap()
+		if !p.isTrusted(addr) {
+			break
+		}
+	}
+	return addr.String()
+}
+
+func (p *ratePolicy) isTrusted(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range p.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
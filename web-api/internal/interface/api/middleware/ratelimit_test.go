@@ -0,0 +1,112 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/auth"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/middleware"
+)
+
+// countingAuthenticator accepts the credentials "good", as a new user on
+// every call, and counts the calls.
+type countingAuthenticator struct {
+	calls int
+}
+
+func (a *countingAuthenticator) Authenticate(ctx context.Context, credentials string) (*auth.Principal, error) {
+	a.calls++
+	if credentials != "good" {
+		return nil, errors.New("invalid")
+	}
+	userID, _ := valueobject.NewUserID(a.calls)
+	return auth.NewPrincipal(userID.String(), userID, valueobject.RoleUser, auth.MethodJWT, nil), nil
+}
+
+func newLimitedHandler(ipLimit, defaultLimit middleware.Limit) (http.Handler, *countingAuthenticator) {
+	mux := http.NewServeMux()
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	mux.Handle("GET /posts", ok)
+	mux.Handle("GET /healthz", ok)
+
+	authenticator := &countingAuthenticator{}
+	limiter := middleware.NewRateLimiter(ipLimit, defaultLimit, nil, nil, time.Minute,
+		func(r *http.Request) string {
+			_, pattern := mux.Handler(r)
+			return pattern
+		},
+		"GET /healthz",
+	)
+	return middleware.Chain(mux,
+		limiter.IPMiddleware,
+		middleware.Authenticate(map[string]middleware.Authenticator{"Bearer": authenticator}),
+		limiter.Middleware,
+	), authenticator
+}
+
+func serve(h http.Handler, path, credentials string) int {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	if credentials != "" {
+		req.Header.Set("Authorization", "Bearer "+credentials)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestIPLimitRunsBeforeAuthentication(t *testing.T) {
+	h, authenticator := newLimitedHandler(middleware.Limit{Rate: 0.001, Burst: 3}, middleware.Limit{})
+
+	for i := range 3 {
+		if code := serve(h, "/posts", "bad"); code != http.StatusUnauthorized {
+			t.Fatalf("request %d status = %d, want %d", i, code, http.StatusUnauthorized)
+		}
+	}
+	if code := serve(h, "/posts", "bad"); code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+	// Valid credentials do not escape the bucket of the address either.
+	if code := serve(h, "/posts", "good"); code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+	if authenticator.calls != 3 {
+		t.Errorf("credentials verified %d times, want 3", authenticator.calls)
+	}
+}
+
+func TestPrincipalLimitRunsAfterAuthentication(t *testing.T) {
+	h, _ := newLimitedHandler(middleware.Limit{}, middleware.Limit{Rate: 0.001, Burst: 1})
+
+	// Each good request signs in as another user with its own bucket.
+	for i := range 3 {
+		if code := serve(h, "/posts", "good"); code != http.StatusOK {
+			t.Fatalf("request %d status = %d, want %d", i, code, http.StatusOK)
+		}
+	}
+	if code := serve(h, "/posts", ""); code != http.StatusOK {
+		t.Fatalf("anonymous status = %d, want %d", code, http.StatusOK)
+	}
+	if code := serve(h, "/posts", ""); code != http.StatusTooManyRequests {
+		t.Fatalf("anonymous status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+}
+
+func TestHealthChecksAreNotLimited(t *testing.T) {
+	h, _ := newLimitedHandler(middleware.Limit{Rate: 0.001, Burst: 1}, middleware.Limit{Rate: 0.001, Burst: 1})
+
+	for i := range 5 {
+		if code := serve(h, "/healthz", ""); code != http.StatusOK {
+			t.Fatalf("request %d status = %d, want %d", i, code, http.StatusOK)
+		}
+	}
+	// Health checks did not use up the address's bucket.
+	if code := serve(h, "/posts", ""); code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", code, http.StatusOK)
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/problem"
+)
+
+// Recover turns a panicking handler into a 500 problem response and logs the
+// panic with its stack. http.ErrAbortHandler is re-raised so that the server
+// still aborts the response as the handler asked.
+func Recover(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := newResponseWriter(w)
+			defer func() {
+				err := recover()
+				if err == nil {
+					return
+				}
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
+				logger.LogAttrs(r.Context(), slog.LevelError, "panic serving request",
+					slog.String("request_id", RequestIDFromContext(r.Context())),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.Any("panic", err),
+					slog.String("stack", string(debug.Stack())),
+				)
+
+				// Once the response has started it cannot be replaced.
+				if !rw.wroteHeader {
+					problem.Write(rw, http.StatusInternalServerError, "Internal server error")
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
@@ -4,27 +4,84 @@ import (
 	"net/http"
 
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/handler"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/middleware"
 )
 
 type Router struct {
-	postHandler *handler.PostHandler
-	userHandler *handler.UserHandler
+	postHandler   *handler.PostHandler
+	userHandler   *handler.UserHandler
+	adminHandler  *handler.AdminHandler
+	apiKeyHandler *handler.APIKeyHandler
+	authHandler   *handler.AuthHandler
+	oidcHandler   *handler.OIDCHandler
+	jwksHandler   *handler.JWKSHandler
+	authRequired  bool
 }
 
-func NewRouter(postHandler *handler.PostHandler, userHandler *handler.UserHandler) *Router {
+// NewRouter builds the API routes. authHandler, oidcHandler and jwksHandler
+// may be nil when the API does not issue its own tokens or has no identity
+// provider configured.
+func NewRouter(postHandler *handler.PostHandler, userHandler *handler.UserHandler, adminHandler *handler.AdminHandler, apiKeyHandler *handler.APIKeyHandler, authHandler *handler.AuthHandler, oidcHandler *handler.OIDCHandler, jwksHandler *handler.JWKSHandler, authRequired bool) *Router {
 	return &Router{
-		postHandler: postHandler,
-		userHandler: userHandler,
+		postHandler:   postHandler,
+		userHandler:   userHandler,
+		adminHandler:  adminHandler,
+		apiKeyHandler: apiKeyHandler,
+		authHandler:   authHandler,
+		oidcHandler:   oidcHandler,
+		jwksHandler:   jwksHandler,
+		authRequired:  authRequired,
 	}
 }
 
 func (r *Router) Setup() *http.ServeMux {
 	mux := http.NewServeMux()
 
-	mux.HandleFunc("/posts", r.postHandler.GetAllPosts)
-	mux.HandleFunc("/posts/", r.postHandler.GetPost)
-	mux.HandleFunc("/users", r.userHandler.GetAllUsers)
-	mux.HandleFunc("/users/", r.userHandler.GetUser)
+	mux.Handle("GET /posts", r.protect(r.postHandler.GetAllPosts))
+	mux.Handle("GET /posts/{id}", r.protect(r.postHandler.GetPost))
+	mux.Handle("POST /posts", authenticated(r.postHandler.CreatePost))
+	mux.Handle("PUT /posts/{id}", authenticated(r.postHandler.UpdatePost))
+	mux.Handle("DELETE /posts/{id}", authenticated(r.postHandler.DeletePost))
+	mux.Handle("GET /users", r.protect(r.userHandler.GetAllUsers))
+	mux.Handle("GET /users/{id}", r.protect(r.userHandler.GetUser))
+
+	mux.Handle("GET /admin/users", authenticated(r.adminHandler.ListUsers))
+	mux.Handle("PUT /admin/users/{id}/role", authenticated(r.adminHandler.ChangeUserRole))
+
+	mux.Handle("POST /api-keys", authenticated(r.apiKeyHandler.CreateAPIKey))
+	mux.Handle("GET /api-keys", authenticated(r.apiKeyHandler.ListAPIKeys))
+	mux.Handle("DELETE /api-keys/{id}", authenticated(r.apiKeyHandler.RevokeAPIKey))
+
+	if r.authHandler != nil {
+		mux.HandleFunc("POST /auth/register", r.authHandler.Register)
+		mux.HandleFunc("POST /auth/login", r.authHandler.Login)
+		mux.HandleFunc("POST /auth/refresh", r.authHandler.Refresh)
+		mux.HandleFunc("POST /auth/logout", r.authHandler.Logout)
+		mux.Handle("POST /auth/revoke", authenticated(r.authHandler.Revoke))
+	}
+
+	if r.oidcHandler != nil {
+		mux.HandleFunc("GET /auth/oidc/login", r.oidcHandler.Login)
+		mux.HandleFunc("GET /auth/oidc/callback", r.oidcHandler.Callback)
+	}
+
+	if r.jwksHandler != nil {
+		mux.HandleFunc("GET /.well-known/jwks.json", r.jwksHandler.GetJWKS)
+	}
 
 	return mux
-}
\ No newline at end of file
+}
+
+// protect requires authentication for read routes when it is enabled.
+func (r *Router) protect(h http.HandlerFunc) http.Handler {
+	if !r.authRequired {
+		return h
+	}
+	return middleware.RequireAuth(h)
+}
+
+// authenticated always requires authentication. Writes and administration
+// are never anonymous.
+func authenticated(h http.HandlerFunc) http.Handler {
+	return middleware.RequireAuth(h)
+}
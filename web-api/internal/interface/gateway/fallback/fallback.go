@@ -0,0 +1,95 @@
+// Package fallback provides repositories that read from the database and
+// turn to an external gateway when the database does not have a record or
+// cannot be reached.
+//
+// Records read from the gateway carry the IDs they have there and are
+// read-only; writes to them fail with ErrReadOnly. Writing them back stores
+// them through an ImportRepository, which matches them by that external ID.
+// The stored record, with its local ID, is then returned in place of the
+// gateway's.
+package fallback
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
+)
+
+// ErrReadOnly is returned for writes to records read from the gateway.
+var ErrReadOnly = errors.New("fallback: record was read from the gateway and is read-only")
+
+// Policy decides when the gateway is consulted.
+type Policy string
+
+const (
+	// PolicyOff only reads from the database.
+	PolicyOff Policy = "off"
+	// PolicyOutage reads from the gateway when the database fails.
+	PolicyOutage Policy = "outage"
+	// PolicyMiss also reads from the gateway when the database does not
+	// have the record. Lists are only read from the gateway on an outage.
+	PolicyMiss Policy = "miss"
+)
+
+// Options configure a fallback repository.
+type Options struct {
+	Policy Policy
+	// WriteBack stores records found in the gateway after a miss. Nothing
+	// is written when it is nil.
+	WriteBack repository.ImportRepository
+	Logger    *slog.Logger
+}
+
+func (o Options) logger() *slog.Logger {
+	if o.Logger == nil {
+		return slog.Default()
+	}
+	return o.Logger
+}
+
+// outage reports whether err means the database could not answer, as
+// opposed to the request being cancelled.
+func outage(ctx context.Context, err error) bool {
+	return err != nil && ctx.Err() == nil && !errors.Is(err, context.Canceled)
+}
+
+// read runs primary and falls back to secondary as the policy says. miss
+// tells whether a successful primary result lacks the record; missed
+// reports that the result came from the gateway after such a miss.
+func read[T any](ctx context.Context, opts Options, entity string, primary, secondary func() (T, error), miss func(T) bool) (result T, missed bool, err error) {
+	result, err = primary()
+	switch {
+	case opts.Policy != PolicyOutage && opts.Policy != PolicyMiss:
+		return result, false, err
+	case err != nil:
+		if !outage(ctx, err) {
+			return result, false, err
+		}
+		opts.logger().WarnContext(ctx, "Database read failed, reading from gateway", "entity", entity, "error", err)
+		result, err = secondary()
+		return result, false, err
+	case opts.Policy == PolicyMiss && miss != nil && miss(result):
+		result, err = secondary()
+		return result, err == nil, err
+	default:
+		return result, false, nil
+	}
+}
+
+// writeBack stores what was found in the gateway and returns the stored
+// record. Failures are logged and do not fail the read; the zero value is
+// returned then, and when nothing is written.
+func writeBack[T any](ctx context.Context, opts Options, entity string, store func(repository.ImportRepository) (T, error)) T {
+	var zero T
+	if opts.WriteBack == nil {
+		return zero
+	}
+	stored, err := store(opts.WriteBack)
+	if err != nil {
+		opts.logger().WarnContext(ctx, "Failed to write back gateway record", "entity", entity, "error", err)
+		return zero
+	}
+	return stored
+}
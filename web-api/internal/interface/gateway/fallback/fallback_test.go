@@ -0,0 +1,138 @@
+package fallback_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/auth"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database/databasetest"
+	dbRepository "github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/gateway/fallback"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/gateway/jsonplaceholder"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/gateway/jsonplaceholder/jsonplaceholdertest"
+	postUseCase "github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/post"
+)
+
+type env struct {
+	users *fallback.UserRepository
+	posts *fallback.PostRepository
+	// post is the last post of the gateway; its ID is not used locally.
+	post *entity.Post
+}
+
+// newEnv serves an author with several posts from the gateway and none
+// from the database, so that IDs on both sides differ.
+func newEnv(t *testing.T, writeBack bool) env {
+	t.Helper()
+
+	server := jsonplaceholdertest.NewServer()
+	t.Cleanup(server.Close)
+	server.AddUser(entity.NewUser(valueobject.UserID{}, "Other", "other", mustEmail(t, "other@example.com")))
+	author := server.AddUser(entity.NewUser(valueobject.UserID{}, "Leanne", "leanne", mustEmail(t, "leanne@example.com")))
+	var post *entity.Post
+	for range 3 {
+		post = server.AddPost(entity.NewPost(valueobject.PostID{}, author.ID(), "title", "body"))
+	}
+
+	db := databasetest.SQLite(t)
+	opts := fallback.Options{Policy: fallback.PolicyMiss}
+	if writeBack {
+		opts.WriteBack = dbRepository.NewImportRepository(db)
+	}
+	userGateway := jsonplaceholder.NewUserGateway(server.URL(), server.Client())
+	postGateway := jsonplaceholder.NewPostGateway(server.URL(), server.Client())
+	return env{
+		users: fallback.NewUserRepository(dbRepository.NewUserRepository(db), userGateway, opts),
+		posts: fallback.NewPostRepository(dbRepository.NewPostRepository(db), postGateway, userGateway, opts),
+		post:  post,
+	}
+}
+
+func mustEmail(t *testing.T, s string) valueobject.Email {
+	t.Helper()
+	email, err := valueobject.NewEmail(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return email
+}
+
+func TestWriteBackReturnsStoredRecord(t *testing.T) {
+	ctx := context.Background()
+	e := newEnv(t, true)
+
+	post, err := e.posts.FindByID(ctx, e.post.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post == nil || post.ReadOnly() {
+		t.Fatalf("FindByID = %v, want the stored post", post)
+	}
+	if post.ID() == e.post.ID() || post.ID().Value() != 1 {
+		t.Errorf("post ID = %s, want the local ID 1", post.ID())
+	}
+	if post.UserID().Value() != 1 {
+		t.Errorf("author ID = %s, want the local ID 1", post.UserID())
+	}
+	if err := e.posts.Update(ctx, post); err != nil {
+		t.Errorf("Update of the stored post: %v", err)
+	}
+
+	user, err := e.users.FindByEmail(ctx, mustEmail(t, "other@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user == nil || user.ReadOnly() || user.ID().Value() != 2 {
+		t.Fatalf("FindByEmail = %v, want the stored user with ID 2", user)
+	}
+}
+
+func TestGatewayRecordsAreReadOnly(t *testing.T) {
+	ctx := context.Background()
+	e := newEnv(t, false)
+
+	post, err := e.posts.FindByID(ctx, e.post.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post == nil || !post.ReadOnly() {
+		t.Fatalf("FindByID = %v, want a read-only gateway post", post)
+	}
+	if err := e.posts.Update(ctx, post); !errors.Is(err, fallback.ErrReadOnly) {
+		t.Errorf("Update = %v, want ErrReadOnly", err)
+	}
+
+	user, err := e.users.FindByUsername(ctx, "leanne")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user == nil || !user.ReadOnly() {
+		t.Fatalf("FindByUsername = %v, want a read-only gateway user", user)
+	}
+	if err := e.users.Update(ctx, user); !errors.Is(err, fallback.ErrReadOnly) {
+		t.Errorf("Update = %v, want ErrReadOnly", err)
+	}
+
+	// The service must not pass the gateway's ID on to the database.
+	service := postUseCase.NewService(e.posts)
+	moderator := auth.NewPrincipal("mod", mustUserID(t, 1), valueobject.RoleModerator, "jwt", nil)
+	ctx = auth.WithPrincipal(ctx, moderator)
+	if _, err := service.UpdatePost(ctx, e.post.ID().String(), "changed", ""); !errors.Is(err, postUseCase.ErrPostReadOnly) {
+		t.Errorf("UpdatePost = %v, want ErrPostReadOnly", err)
+	}
+	if err := service.DeletePost(ctx, e.post.ID().String()); !errors.Is(err, postUseCase.ErrPostReadOnly) {
+		t.Errorf("DeletePost = %v, want ErrPostReadOnly", err)
+	}
+}
+
+func mustUserID(t *testing.T, id int) valueobject.UserID {
+	t.Helper()
+	userID, err := valueobject.NewUserID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return userID
+}
@@ -0,0 +1,98 @@
+package fallback
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+// PostGateway reads posts from the external service.
+type PostGateway interface {
+	FindAll(ctx context.Context) ([]*entity.Post, error)
+	FindByID(ctx context.Context, id valueobject.PostID) (*entity.Post, error)
+	FindByUserID(ctx context.Context, userID valueobject.UserID) ([]*entity.Post, error)
+}
+
+// PostRepository reads posts from db and falls back to gateway. Writes
+// only go to db, and fail for posts read from gateway. When a post is written back, its author is read from
+// authors and written along with it.
+type PostRepository struct {
+	db      repository.PostRepository
+	gateway PostGateway
+	authors UserGateway
+	opts    Options
+}
+
+func NewPostRepository(db repository.PostRepository, gateway PostGateway, authors UserGateway, opts Options) *PostRepository {
+	return &PostRepository{
+		db:      db,
+		gateway: gateway,
+		authors: authors,
+		opts:    opts,
+	}
+}
+
+func (r *PostRepository) FindAll(ctx context.Context) ([]*entity.Post, error) {
+	posts, _, err := read(ctx, r.opts, "post",
+		func() ([]*entity.Post, error) { return r.db.FindAll(ctx) },
+		func() ([]*entity.Post, error) { return r.gateway.FindAll(ctx) },
+		nil,
+	)
+	return posts, err
+}
+
+func (r *PostRepository) FindByID(ctx context.Context, id valueobject.PostID) (*entity.Post, error) {
+	post, missed, err := read(ctx, r.opts, "post",
+		func() (*entity.Post, error) { return r.db.FindByID(ctx, id) },
+		func() (*entity.Post, error) { return r.gateway.FindByID(ctx, id) },
+		func(post *entity.Post) bool { return post == nil },
+	)
+	if err != nil || !missed || post == nil {
+		return post, err
+	}
+
+	stored := writeBack(ctx, r.opts, "post", func(store repository.ImportRepository) (*entity.Post, error) {
+		author, err := r.authors.FindByID(ctx, post.UserID())
+		if err != nil {
+			return nil, err
+		}
+		if author == nil {
+			return nil, fmt.Errorf("post %s: author %s not found", post.ID(), post.UserID())
+		}
+		if _, err := store.Import(ctx, []*entity.User{author}, []*entity.Post{post}, false); err != nil {
+			return nil, err
+		}
+		return store.FindPostByExternalID(ctx, post.ID())
+	})
+	if stored != nil {
+		return stored, nil
+	}
+	return post, nil
+}
+
+func (r *PostRepository) FindByUserID(ctx context.Context, userID valueobject.UserID) ([]*entity.Post, error) {
+	posts, _, err := read(ctx, r.opts, "post",
+		func() ([]*entity.Post, error) { return r.db.FindByUserID(ctx, userID) },
+		func() ([]*entity.Post, error) { return r.gateway.FindByUserID(ctx, userID) },
+		nil,
+	)
+	return posts, err
+}
+
+func (r *PostRepository) Save(ctx context.Context, post *entity.Post) (*entity.Post, error) {
+	return r.db.Save(ctx, post)
+}
+
+func (r *PostRepository) Update(ctx context.Context, post *entity.Post) error {
+	if post.ReadOnly() {
+		return ErrReadOnly
+	}
+	return r.db.Update(ctx, post)
+}
+
+func (r *PostRepository) Delete(ctx context.Context, id valueobject.PostID) error {
+	return r.db.Delete(ctx, id)
+}
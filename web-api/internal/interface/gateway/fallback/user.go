@@ -0,0 +1,92 @@
+package fallback
+
+import (
+	"context"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+// UserGateway reads users from the external service.
+type UserGateway interface {
+	FindAll(ctx context.Context) ([]*entity.User, error)
+	FindByID(ctx context.Context, id valueobject.UserID) (*entity.User, error)
+	FindByEmail(ctx context.Context, email valueobject.Email) (*entity.User, error)
+	FindByUsername(ctx context.Context, username string) (*entity.User, error)
+}
+
+// UserRepository reads users from db and falls back to gateway. Writes
+// only go to db, and fail for users read from gateway.
+type UserRepository struct {
+	db      repository.UserRepository
+	gateway UserGateway
+	opts    Options
+}
+
+func NewUserRepository(db repository.UserRepository, gateway UserGateway, opts Options) *UserRepository {
+	return &UserRepository{
+		db:      db,
+		gateway: gateway,
+		opts:    opts,
+	}
+}
+
+func (r *UserRepository) FindAll(ctx context.Context) ([]*entity.User, error) {
+	users, _, err := read(ctx, r.opts, "user",
+		func() ([]*entity.User, error) { return r.db.FindAll(ctx) },
+		func() ([]*entity.User, error) { return r.gateway.FindAll(ctx) },
+		nil,
+	)
+	return users, err
+}
+
+func (r *UserRepository) FindByID(ctx context.Context, id valueobject.UserID) (*entity.User, error) {
+	return r.find(ctx,
+		func() (*entity.User, error) { return r.db.FindByID(ctx, id) },
+		func() (*entity.User, error) { return r.gateway.FindByID(ctx, id) },
+	)
+}
+
+func (r *UserRepository) FindByEmail(ctx context.Context, email valueobject.Email) (*entity.User, error) {
+	return r.find(ctx,
+		func() (*entity.User, error) { return r.db.FindByEmail(ctx, email) },
+		func() (*entity.User, error) { return r.gateway.FindByEmail(ctx, email) },
+	)
+}
+
+func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return r.find(ctx,
+		func() (*entity.User, error) { return r.db.FindByUsername(ctx, username) },
+		func() (*entity.User, error) { return r.gateway.FindByUsername(ctx, username) },
+	)
+}
+
+func (r *UserRepository) Save(ctx context.Context, user *entity.User) (*entity.User, error) {
+	return r.db.Save(ctx, user)
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
+	if user.ReadOnly() {
+		return ErrReadOnly
+	}
+	return r.db.Update(ctx, user)
+}
+
+func (r *UserRepository) find(ctx context.Context, primary, secondary func() (*entity.User, error)) (*entity.User, error) {
+	user, missed, err := read(ctx, r.opts, "user", primary, secondary, func(user *entity.User) bool { return user == nil })
+	if err != nil || !missed || user == nil {
+		return user, err
+	}
+
+	stored := writeBack(ctx, r.opts, "user", func(store repository.ImportRepository) (*entity.User, error) {
+		if _, err := store.Import(ctx, []*entity.User{user}, nil, false); err != nil {
+			return nil, err
+		}
+		return store.FindUserByExternalID(ctx, user.ID())
+	})
+	if stored != nil {
+		return stored, nil
+	}
+	return user, nil
+}
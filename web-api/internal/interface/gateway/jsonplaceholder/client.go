@@ -0,0 +1,32 @@
+package jsonplaceholder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrReadOnly is returned by the write methods. JSONPlaceholder accepts
+// writes but does not store them, so the gateways refuse them instead.
+var ErrReadOnly = errors.New("jsonplaceholder: gateway is read-only")
+
+// get issues a GET bound to ctx, so that cancellation and trace context
+// reach the outbound call. Error statuses other than 404, which callers
+// handle as a missing record, are returned as errors.
+func get(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode != http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return resp, nil
+}
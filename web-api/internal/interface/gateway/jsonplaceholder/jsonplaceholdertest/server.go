@@ -0,0 +1,177 @@
+// Package jsonplaceholdertest provides an in-process fake of the
+// JSONPlaceholder API, so that the gateways can be exercised without
+// network access.
+package jsonplaceholdertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/api/dto"
+)
+
+// Server serves the users and posts added to it, read-only, with the
+// routes and filters of JSONPlaceholder that the gateways use.
+type Server struct {
+	server *httptest.Server
+
+	mu     sync.Mutex
+	users  []dto.UserResponse
+	posts  []dto.PostResponse
+	status int
+}
+
+// NewServer starts an empty server on a loopback listener. Callers must
+// Close it.
+func NewServer() *Server {
+	s := &Server{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users", s.listUsers)
+	mux.HandleFunc("GET /users/{id}", s.getUser)
+	mux.HandleFunc("GET /posts", s.listPosts)
+	mux.HandleFunc("GET /posts/{id}", s.getPost)
+	s.server = httptest.NewServer(s.failing(mux))
+
+	return s
+}
+
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Client returns an HTTP client that can reach the server.
+func (s *Server) Client() *http.Client {
+	return s.server.Client()
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// AddUser stores user under the next free ID and returns it with that ID.
+func (s *Server) AddUser(user *entity.User) *entity.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := len(s.users) + 1
+	s.users = append(s.users, dto.UserResponse{
+		ID:       id,
+		Name:     user.Name(),
+		Username: user.Username(),
+		Email:    user.Email().String(),
+	})
+
+	userID, _ := valueobject.NewUserID(id)
+	return entity.NewUser(userID, user.Name(), user.Username(), user.Email())
+}
+
+// AddPost stores post under the next free ID and returns it with that ID.
+func (s *Server) AddPost(post *entity.Post) *entity.Post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := len(s.posts) + 1
+	s.posts = append(s.posts, dto.PostResponse{
+		UserID: post.UserID().Value(),
+		ID:     id,
+		Title:  post.Title(),
+		Body:   post.Body(),
+	})
+
+	postID, _ := valueobject.NewPostID(id)
+	return entity.NewPost(postID, post.UserID(), post.Title(), post.Body())
+}
+
+// SetStatus makes every request fail with status, to simulate an outage.
+// Zero restores normal responses.
+func (s *Server) SetStatus(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func (s *Server) failing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		status := s.status
+		s.mu.Unlock()
+
+		if status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listUsers filters by the email and username query parameters, which must
+// match exactly.
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	users := []dto.UserResponse{}
+	for _, u := range s.users {
+		if query.Has("email") && u.Email != query.Get("email") {
+			continue
+		}
+		if query.Has("username") && u.Username != query.Get("username") {
+			continue
+		}
+		users = append(users, u)
+	}
+	writeJSON(w, http.StatusOK, users)
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id < 1 || id > len(s.users) {
+		writeJSON(w, http.StatusNotFound, struct{}{})
+		return
+	}
+	writeJSON(w, http.StatusOK, s.users[id-1])
+}
+
+// listPosts filters by the userId query parameter.
+func (s *Server) listPosts(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	posts := []dto.PostResponse{}
+	for _, p := range s.posts {
+		if query.Has("userId") && strconv.Itoa(p.UserID) != query.Get("userId") {
+			continue
+		}
+		posts = append(posts, p)
+	}
+	writeJSON(w, http.StatusOK, posts)
+}
+
+func (s *Server) getPost(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id < 1 || id > len(s.posts) {
+		writeJSON(w, http.StatusNotFound, struct{}{})
+		return
+	}
+	writeJSON(w, http.StatusOK, s.posts[id-1])
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
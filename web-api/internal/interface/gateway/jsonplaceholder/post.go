@@ -24,7 +24,7 @@ func NewPostGateway(baseURL string, httpClient *http.Client) *PostGateway {
 }
 
 func (g *PostGateway) FindAll(ctx context.Context) ([]*entity.Post, error) {
-	resp, err := g.httpClient.Get(g.baseURL + "/posts")
+	resp, err := get(ctx, g.httpClient, g.baseURL+"/posts")
 	if err != nil {
 		return nil, err
 	}
@@ -37,16 +37,14 @@ func (g *PostGateway) FindAll(ctx context.Context) ([]*entity.Post, error) {
 
 	posts := make([]*entity.Post, len(dtos))
 	for i, dto := range dtos {
-		postID, _ := valueobject.NewPostID(dto.ID)
-		userID, _ := valueobject.NewUserID(dto.UserID)
-		posts[i] = entity.NewPost(postID, userID, dto.Title, dto.Body)
+		posts[i] = toPost(dto)
 	}
 
 	return posts, nil
 }
 
 func (g *PostGateway) FindByID(ctx context.Context, id valueobject.PostID) (*entity.Post, error) {
-	resp, err := g.httpClient.Get(fmt.Sprintf("%s/posts/%s", g.baseURL, id.String()))
+	resp, err := get(ctx, g.httpClient, fmt.Sprintf("%s/posts/%s", g.baseURL, id.String()))
 	if err != nil {
 		return nil, err
 	}
@@ -61,15 +59,11 @@ func (g *PostGateway) FindByID(ctx context.Context, id valueobject.PostID) (*ent
 		return nil, err
 	}
 
-	postID, _ := valueobject.NewPostID(dto.ID)
-	userID, _ := valueobject.NewUserID(dto.UserID)
-	post := entity.NewPost(postID, userID, dto.Title, dto.Body)
-
-	return post, nil
+	return toPost(dto), nil
 }
 
 func (g *PostGateway) FindByUserID(ctx context.Context, userID valueobject.UserID) ([]*entity.Post, error) {
-	resp, err := g.httpClient.Get(fmt.Sprintf("%s/posts?userId=%s", g.baseURL, userID.String()))
+	resp, err := get(ctx, g.httpClient, fmt.Sprintf("%s/posts?userId=%s", g.baseURL, userID.String()))
 	if err != nil {
 		return nil, err
 	}
@@ -82,10 +76,30 @@ func (g *PostGateway) FindByUserID(ctx context.Context, userID valueobject.UserI
 
 	posts := make([]*entity.Post, len(dtos))
 	for i, dto := range dtos {
-		postID, _ := valueobject.NewPostID(dto.ID)
-		userID, _ := valueobject.NewUserID(dto.UserID)
-		posts[i] = entity.NewPost(postID, userID, dto.Title, dto.Body)
+		posts[i] = toPost(dto)
 	}
 
 	return posts, nil
-}
\ No newline at end of file
+}
+
+// toPost maps a post of the service. It is read-only, as it carries the
+// service's IDs.
+func toPost(dto dto.PostResponse) *entity.Post {
+	postID, _ := valueobject.NewPostID(dto.ID)
+	userID, _ := valueobject.NewUserID(dto.UserID)
+	post := entity.NewPost(postID, userID, dto.Title, dto.Body)
+	post.MarkReadOnly()
+	return post
+}
+
+func (g *PostGateway) Save(ctx context.Context, post *entity.Post) (*entity.Post, error) {
+	return nil, ErrReadOnly
+}
+
+func (g *PostGateway) Update(ctx context.Context, post *entity.Post) error {
+	return ErrReadOnly
+}
+
+func (g *PostGateway) Delete(ctx context.Context, id valueobject.PostID) error {
+	return ErrReadOnly
+}
@@ -0,0 +1,23 @@
+package jsonplaceholder_test
+
+import (
+	"testing"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository/repositorytest"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/gateway/jsonplaceholder"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/gateway/jsonplaceholder/jsonplaceholdertest"
+)
+
+func TestGateways(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Subject {
+		server := jsonplaceholdertest.NewServer()
+		t.Cleanup(server.Close)
+		return repositorytest.Subject{
+			Users:    jsonplaceholder.NewUserGateway(server.URL(), server.Client()),
+			Posts:    jsonplaceholder.NewPostGateway(server.URL(), server.Client()),
+			ReadOnly: true,
+			AddUser:  server.AddUser,
+			AddPost:  server.AddPost,
+		}
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
 
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
@@ -24,7 +26,7 @@ func NewUserGateway(baseURL string, httpClient *http.Client) *UserGateway {
 }
 
 func (g *UserGateway) FindAll(ctx context.Context) ([]*entity.User, error) {
-	resp, err := g.httpClient.Get(g.baseURL + "/users")
+	resp, err := get(ctx, g.httpClient, g.baseURL+"/users")
 	if err != nil {
 		return nil, err
 	}
@@ -37,16 +39,14 @@ func (g *UserGateway) FindAll(ctx context.Context) ([]*entity.User, error) {
 
 	users := make([]*entity.User, len(dtos))
 	for i, dto := range dtos {
-		userID, _ := valueobject.NewUserID(dto.ID)
-		email, _ := valueobject.NewEmail(dto.Email)
-		users[i] = entity.NewUser(userID, dto.Name, dto.Username, email)
+		users[i] = toUser(dto)
 	}
 
 	return users, nil
 }
 
 func (g *UserGateway) FindByID(ctx context.Context, id valueobject.UserID) (*entity.User, error) {
-	resp, err := g.httpClient.Get(fmt.Sprintf("%s/users/%s", g.baseURL, id.String()))
+	resp, err := get(ctx, g.httpClient, fmt.Sprintf("%s/users/%s", g.baseURL, id.String()))
 	if err != nil {
 		return nil, err
 	}
@@ -61,15 +61,11 @@ func (g *UserGateway) FindByID(ctx context.Context, id valueobject.UserID) (*ent
 		return nil, err
 	}
 
-	userID, _ := valueobject.NewUserID(dto.ID)
-	email, _ := valueobject.NewEmail(dto.Email)
-	user := entity.NewUser(userID, dto.Name, dto.Username, email)
-
-	return user, nil
+	return toUser(dto), nil
 }
 
 func (g *UserGateway) FindByEmail(ctx context.Context, email valueobject.Email) (*entity.User, error) {
-	resp, err := g.httpClient.Get(fmt.Sprintf("%s/users?email=%s", g.baseURL, email.String()))
+	resp, err := get(ctx, g.httpClient, fmt.Sprintf("%s/users?email=%s", g.baseURL, url.QueryEscape(email.String())))
 	if err != nil {
 		return nil, err
 	}
@@ -80,14 +76,49 @@ func (g *UserGateway) FindByEmail(ctx context.Context, email valueobject.Email)
 		return nil, err
 	}
 
-	if len(dtos) == 0 {
+	// The filter is applied by the service; only an exact match counts.
+	i := slices.IndexFunc(dtos, func(d dto.UserResponse) bool { return d.Email == email.String() })
+	if i < 0 {
 		return nil, nil
 	}
 
-	dto := dtos[0]
+	return toUser(dtos[i]), nil
+}
+
+func (g *UserGateway) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
+	resp, err := get(ctx, g.httpClient, fmt.Sprintf("%s/users?username=%s", g.baseURL, url.QueryEscape(username)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var dtos []dto.UserResponse
+	if err := json.NewDecoder(resp.Body).Decode(&dtos); err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(dtos, func(d dto.UserResponse) bool { return d.Username == username })
+	if i < 0 {
+		return nil, nil
+	}
+
+	return toUser(dtos[i]), nil
+}
+
+// toUser maps a user of the service. It is read-only, as it carries the
+// service's ID.
+func toUser(dto dto.UserResponse) *entity.User {
 	userID, _ := valueobject.NewUserID(dto.ID)
-	emailVO, _ := valueobject.NewEmail(dto.Email)
-	user := entity.NewUser(userID, dto.Name, dto.Username, emailVO)
+	email, _ := valueobject.NewEmail(dto.Email)
+	user := entity.NewUser(userID, dto.Name, dto.Username, email)
+	user.MarkReadOnly()
+	return user
+}
 
-	return user, nil
-}
\ No newline at end of file
+func (g *UserGateway) Save(ctx context.Context, user *entity.User) (*entity.User, error) {
+	return nil, ErrReadOnly
+}
+
+func (g *UserGateway) Update(ctx context.Context, user *entity.User) error {
+	return ErrReadOnly
+}
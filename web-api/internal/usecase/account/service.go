@@ -0,0 +1,293 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 256
+)
+
+var (
+	ErrInvalidInput        = errors.New("invalid input")
+	ErrUsernameTaken       = errors.New("username already registered")
+	ErrEmailTaken          = errors.New("email already registered")
+	ErrInvalidCredentials  = errors.New("invalid username or password")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+)
+
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(hash, password string) (match bool, needsRehash bool, err error)
+}
+
+type TokenIssuer interface {
+	IssueAccessToken(userID valueobject.UserID, role valueobject.Role, scopes []string) (string, time.Time, error)
+}
+
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+type Service struct {
+	userRepo        repository.UserRepository
+	tokenRepo       repository.RefreshTokenRepository
+	hasher          PasswordHasher
+	issuer          TokenIssuer
+	refreshTokenTTL time.Duration
+
+	dummyHashOnce sync.Once
+	dummyHash     string
+}
+
+func NewService(userRepo repository.UserRepository, tokenRepo repository.RefreshTokenRepository, hasher PasswordHasher, issuer TokenIssuer, refreshTokenTTL time.Duration) *Service {
+	return &Service{
+		userRepo:        userRepo,
+		tokenRepo:       tokenRepo,
+		hasher:          hasher,
+		issuer:          issuer,
+		refreshTokenTTL: refreshTokenTTL,
+	}
+}
+
+func (s *Service) Register(ctx context.Context, name, username, emailStr, password string) (*entity.User, error) {
+	name = strings.TrimSpace(name)
+	username = strings.TrimSpace(username)
+	if name == "" || username == "" {
+		return nil, fmt.Errorf("%w: name and username are required", ErrInvalidInput)
+	}
+
+	email, err := valueobject.NewEmail(emailStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	if err := validatePassword(password); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.userRepo.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, errors.New("failed to register user")
+	}
+	if existing != nil {
+		return nil, ErrUsernameTaken
+	}
+
+	existing, err = s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, errors.New("failed to register user")
+	}
+	if existing != nil {
+		return nil, ErrEmailTaken
+	}
+
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return nil, errors.New("failed to register user")
+	}
+
+	user := entity.NewUser(valueobject.UserID{}, name, username, email)
+	user.SetPasswordHash(hash)
+
+	saved, err := s.userRepo.Save(ctx, user)
+	if errors.Is(err, repository.ErrDuplicate) {
+		// A concurrent registration took the username or email after the
+		// checks above.
+		if taken, _ := s.userRepo.FindByUsername(ctx, username); taken != nil {
+			return nil, ErrUsernameTaken
+		}
+		return nil, ErrEmailTaken
+	}
+	if err != nil {
+		return nil, errors.New("failed to register user")
+	}
+	return saved, nil
+}
+
+// Login authenticates by username or email and issues a new token pair.
+func (s *Service) Login(ctx context.Context, login, password string) (*TokenPair, error) {
+	user, err := s.findByLogin(ctx, strings.TrimSpace(login))
+	if err != nil {
+		return nil, errors.New("failed to login")
+	}
+
+	if user == nil || user.PasswordHash() == "" {
+		// Spend the same time as a real verification so that response
+		// times do not reveal which accounts exist.
+		s.hasher.Verify(s.getDummyHash(), password)
+		return nil, ErrInvalidCredentials
+	}
+
+	match, needsRehash, err := s.hasher.Verify(user.PasswordHash(), password)
+	if err != nil || !match {
+		return nil, ErrInvalidCredentials
+	}
+
+	if needsRehash {
+		if hash, err := s.hasher.Hash(password); err == nil {
+			user.SetPasswordHash(hash)
+			// A failed upgrade is retried on the next login.
+			_ = s.userRepo.Update(ctx, user)
+		}
+	}
+
+	return s.issue(ctx, user, newID())
+}
+
+// Refresh rotates a refresh token. Presenting a token that has already been
+// rotated revokes every token of its family.
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	token, err := s.tokenRepo.FindByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, errors.New("failed to refresh token")
+	}
+	if token == nil || token.IsExpired(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if token.IsRevoked() {
+		if err := s.tokenRepo.RevokeFamily(ctx, token.FamilyID()); err != nil {
+			return nil, errors.New("failed to refresh token")
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	revoked, err := s.tokenRepo.Revoke(ctx, token.ID())
+	if err != nil {
+		return nil, errors.New("failed to refresh token")
+	}
+	if !revoked {
+		// Another request rotated the same token concurrently.
+		if err := s.tokenRepo.RevokeFamily(ctx, token.FamilyID()); err != nil {
+			return nil, errors.New("failed to refresh token")
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	// Load the user again so that role changes apply to the new token.
+	user, err := s.userRepo.FindByID(ctx, token.UserID())
+	if err != nil {
+		return nil, errors.New("failed to refresh token")
+	}
+	if user == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return s.issue(ctx, user, token.FamilyID())
+}
+
+// Logout revokes the session the refresh token belongs to.
+func (s *Service) Logout(ctx context.Context, refreshToken string) error {
+	token, err := s.tokenRepo.FindByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return errors.New("failed to logout")
+	}
+	if token == nil {
+		return ErrInvalidRefreshToken
+	}
+
+	if err := s.tokenRepo.RevokeFamily(ctx, token.FamilyID()); err != nil {
+		return errors.New("failed to logout")
+	}
+	return nil
+}
+
+// RevokeAll revokes every refresh token of the user, signing out all of
+// their sessions once the current access tokens expire.
+func (s *Service) RevokeAll(ctx context.Context, userID valueobject.UserID) error {
+	if err := s.tokenRepo.RevokeAllForUser(ctx, userID); err != nil {
+		return errors.New("failed to revoke tokens")
+	}
+	return nil
+}
+
+func (s *Service) issue(ctx context.Context, user *entity.User, familyID string) (*TokenPair, error) {
+	accessToken, accessExpiresAt, err := s.issuer.IssueAccessToken(user.ID(), user.Role(), nil)
+	if err != nil {
+		return nil, errors.New("failed to issue token")
+	}
+
+	refreshToken, err := randomToken()
+	if err != nil {
+		return nil, errors.New("failed to issue token")
+	}
+	refreshExpiresAt := time.Now().Add(s.refreshTokenTTL)
+
+	token := entity.NewRefreshToken(newID(), user.ID(), familyID, hashToken(refreshToken), refreshExpiresAt, time.Time{})
+	if err := s.tokenRepo.Save(ctx, token); err != nil {
+		return nil, errors.New("failed to issue token")
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+func (s *Service) findByLogin(ctx context.Context, login string) (*entity.User, error) {
+	if strings.Contains(login, "@") {
+		email, err := valueobject.NewEmail(login)
+		if err != nil {
+			return nil, nil
+		}
+		return s.userRepo.FindByEmail(ctx, email)
+	}
+	return s.userRepo.FindByUsername(ctx, login)
+}
+
+func (s *Service) getDummyHash() string {
+	s.dummyHashOnce.Do(func() {
+		s.dummyHash, _ = s.hasher.Hash("dummy password")
+	})
+	return s.dummyHash
+}
+
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("%w: password must be at least %d characters", ErrInvalidInput, minPasswordLength)
+	}
+	if len(password) > maxPasswordLength {
+		return fmt.Errorf("%w: password must be at most %d characters", ErrInvalidInput, maxPasswordLength)
+	}
+	return nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,70 @@
+package account_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/memory"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/password"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/account"
+)
+
+// racingUsers hides the stored users until Save is called, as if another
+// registration stored them after the checks that precede it.
+type racingUsers struct {
+	*memory.UserRepository
+	saved bool
+}
+
+func (r *racingUsers) FindByEmail(ctx context.Context, email valueobject.Email) (*entity.User, error) {
+	if !r.saved {
+		return nil, nil
+	}
+	return r.UserRepository.FindByEmail(ctx, email)
+}
+
+func (r *racingUsers) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
+	if !r.saved {
+		return nil, nil
+	}
+	return r.UserRepository.FindByUsername(ctx, username)
+}
+
+func (r *racingUsers) Save(ctx context.Context, user *entity.User) (*entity.User, error) {
+	r.saved = true
+	return r.UserRepository.Save(ctx, user)
+}
+
+func TestRegisterMapsConcurrentDuplicates(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		email    string
+		want     error
+	}{
+		{name: "username", username: "alice", email: "other@example.com", want: account.ErrUsernameTaken},
+		{name: "email", username: "other", email: "alice@example.com", want: account.ErrEmailTaken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := memory.NewUserRepository()
+			email, _ := valueobject.NewEmail("alice@example.com")
+			if _, err := users.Save(context.Background(), entity.NewUser(valueobject.UserID{}, "Alice", "alice", email)); err != nil {
+				t.Fatal(err)
+			}
+
+			hasher := password.NewArgon2idHasher(password.Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+			s := account.NewService(&racingUsers{UserRepository: users}, memory.NewRefreshTokenRepository(), hasher, nil, time.Hour)
+
+			_, err := s.Register(context.Background(), "New", tt.username, tt.email, "long enough password")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Register = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package account
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+var (
+	ErrInvalidSSOState     = errors.New("invalid or expired login state")
+	ErrSSOFailed           = errors.New("identity provider login failed")
+	ErrEmailNotVerified    = errors.New("identity provider did not verify the email address")
+	ErrNoUsernameAvailable = errors.New("no username available")
+)
+
+// ExternalIdentity is the verified result of a login at an identity
+// provider.
+type ExternalIdentity struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+type IdentityProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	// Exchange redeems an authorization code and verifies the returned ID
+	// token, including its nonce.
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*ExternalIdentity, error)
+}
+
+// SSOSession is the per-login state that must survive the redirect to the
+// identity provider. It is kept by the client, so it holds no secrets that
+// the user may not see.
+type SSOSession struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
+
+type SSOService struct {
+	accounts     *Service
+	identityRepo repository.IdentityRepository
+	provider     IdentityProvider
+}
+
+func NewSSOService(accounts *Service, identityRepo repository.IdentityRepository, provider IdentityProvider) *SSOService {
+	return &SSOService{
+		accounts:     accounts,
+		identityRepo: identityRepo,
+		provider:     provider,
+	}
+}
+
+// Begin starts an authorization code flow with PKCE and returns the URL to
+// send the user to.
+func (s *SSOService) Begin(ctx context.Context) (*SSOSession, string, error) {
+	var session SSOSession
+	for _, v := range []*string{&session.State, &session.Nonce, &session.Verifier} {
+		token, err := randomToken()
+		if err != nil {
+			return nil, "", errors.New("failed to start login")
+		}
+		*v = token
+	}
+
+	url, err := s.provider.AuthCodeURL(ctx, session.State, session.Nonce, codeChallenge(session.Verifier))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrSSOFailed, err)
+	}
+	return &session, url, nil
+}
+
+// Complete finishes the flow started by Begin. The external identity is
+// linked to a local user by verified email, and a user is created on first
+// login if none exists.
+func (s *SSOService) Complete(ctx context.Context, session *SSOSession, state, code string) (*TokenPair, error) {
+	if session == nil || state == "" || subtle.ConstantTimeCompare([]byte(session.State), []byte(state)) != 1 {
+		return nil, ErrInvalidSSOState
+	}
+
+	identity, err := s.provider.Exchange(ctx, code, session.Verifier, session.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSSOFailed, err)
+	}
+
+	user, err := s.resolveUser(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.accounts.issue(ctx, user, newID())
+}
+
+func (s *SSOService) resolveUser(ctx context.Context, identity *ExternalIdentity) (*entity.User, error) {
+	linked, err := s.identityRepo.FindBySubject(ctx, identity.Issuer, identity.Subject)
+	if err != nil {
+		return nil, errors.New("failed to login")
+	}
+	if linked != nil {
+		user, err := s.accounts.userRepo.FindByID(ctx, linked.UserID())
+		if err != nil {
+			return nil, errors.New("failed to login")
+		}
+		if user != nil {
+			return user, nil
+		}
+	}
+
+	if !identity.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+	email, err := valueobject.NewEmail(identity.Email)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSSOFailed, err)
+	}
+
+	user, err := s.accounts.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, errors.New("failed to login")
+	}
+	if user == nil {
+		user, err = s.createUser(ctx, identity, email)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.identityRepo.Save(ctx, entity.NewIdentity(identity.Issuer, identity.Subject, user.ID())); err != nil {
+		return nil, errors.New("failed to login")
+	}
+	return user, nil
+}
+
+var usernameDisallowed = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// createUser provisions a user just in time. Such users have no password
+// and can only sign in through the identity provider.
+func (s *SSOService) createUser(ctx context.Context, identity *ExternalIdentity, email valueobject.Email) (*entity.User, error) {
+	base := usernameDisallowed.ReplaceAllString(identity.PreferredUsername, "")
+	if base == "" {
+		local, _, _ := strings.Cut(email.String(), "@")
+		base = usernameDisallowed.ReplaceAllString(local, "")
+	}
+	if base == "" {
+		base = "user"
+	}
+
+	username := base
+	for i := 2; ; i++ {
+		existing, err := s.accounts.userRepo.FindByUsername(ctx, username)
+		if err != nil {
+			return nil, errors.New("failed to login")
+		}
+		if existing == nil {
+			break
+		}
+		if i > 100 {
+			return nil, ErrNoUsernameAvailable
+		}
+		username = fmt.Sprintf("%s%d", base, i)
+	}
+
+	name := strings.TrimSpace(identity.Name)
+	if name == "" {
+		name = username
+	}
+
+	user, err := s.accounts.userRepo.Save(ctx, entity.NewUser(valueobject.UserID{}, name, username, email))
+	if err != nil {
+		return nil, errors.New("failed to login")
+	}
+	return user, nil
+}
+
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
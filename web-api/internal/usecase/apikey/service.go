@@ -0,0 +1,188 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/auth"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/policy"
+)
+
+const (
+	keyPrefix = "wak_"
+	// lastUsedResolution limits last-used tracking to one write per key
+	// and interval instead of one per request.
+	lastUsedResolution = time.Minute
+)
+
+var (
+	ErrInvalidInput   = errors.New("invalid input")
+	ErrInvalidKey     = errors.New("invalid API key")
+	ErrAPIKeyNotFound = errors.New("API key not found")
+)
+
+type Service struct {
+	keyRepo  repository.APIKeyRepository
+	userRepo repository.UserRepository
+}
+
+func NewService(keyRepo repository.APIKeyRepository, userRepo repository.UserRepository) *Service {
+	return &Service{
+		keyRepo:  keyRepo,
+		userRepo: userRepo,
+	}
+}
+
+// Create issues a key for the authenticated user. The plaintext key is only
+// returned here; afterwards just its hash is known.
+func (s *Service) Create(ctx context.Context, name string, scopes []string, ttl time.Duration) (*entity.APIKey, string, error) {
+	principal, err := policy.CanManageAPIKeys(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if len(scopes) == 0 {
+		return nil, "", fmt.Errorf("%w: at least one scope is required", ErrInvalidInput)
+	}
+	for _, scope := range scopes {
+		if !auth.IsValidScope(scope) {
+			return nil, "", fmt.Errorf("%w: unknown scope %q", ErrInvalidInput, scope)
+		}
+	}
+	if ttl < 0 {
+		return nil, "", fmt.Errorf("%w: expiry must not be negative", ErrInvalidInput)
+	}
+
+	id, secret, err := generate()
+	if err != nil {
+		return nil, "", errors.New("failed to create API key")
+	}
+
+	now := time.Now()
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = now.Add(ttl)
+	}
+
+	plaintext := keyPrefix + id + "_" + secret
+	key := entity.NewAPIKey(id, principal.UserID(), name, hashKey(plaintext), scopes, expiresAt, time.Time{}, now)
+	if err := s.keyRepo.Save(ctx, key); err != nil {
+		return nil, "", errors.New("failed to create API key")
+	}
+	return key, plaintext, nil
+}
+
+func (s *Service) List(ctx context.Context) ([]*entity.APIKey, error) {
+	principal, err := policy.CanManageAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.keyRepo.FindByUserID(ctx, principal.UserID())
+	if err != nil {
+		return nil, errors.New("failed to get API keys")
+	}
+	return keys, nil
+}
+
+// Revoke deletes a key of the authenticated user. Admins may revoke any key.
+func (s *Service) Revoke(ctx context.Context, id string) error {
+	principal, err := policy.CanManageAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	key, err := s.keyRepo.FindByID(ctx, id)
+	if err != nil {
+		return errors.New("failed to revoke API key")
+	}
+	if key == nil {
+		return ErrAPIKeyNotFound
+	}
+	if key.UserID() != principal.UserID() && !principal.Role().Includes(valueobject.RoleAdmin) {
+		// Do not reveal that the key exists.
+		return ErrAPIKeyNotFound
+	}
+
+	if err := s.keyRepo.Delete(ctx, id); err != nil {
+		return errors.New("failed to revoke API key")
+	}
+	return nil
+}
+
+// Authenticate resolves the credentials of an "Authorization: ApiKey ..."
+// header to the principal of the key's owner, restricted to its scopes.
+func (s *Service) Authenticate(ctx context.Context, credentials string) (*auth.Principal, error) {
+	rest, ok := strings.CutPrefix(credentials, keyPrefix)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	id, _, ok := strings.Cut(rest, "_")
+	if !ok || id == "" {
+		return nil, ErrInvalidKey
+	}
+
+	key, err := s.keyRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("failed to verify API key")
+	}
+	if key == nil {
+		return nil, ErrInvalidKey
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash()), []byte(hashKey(credentials))) != 1 {
+		return nil, ErrInvalidKey
+	}
+
+	now := time.Now()
+	if key.IsExpired(now) {
+		return nil, ErrInvalidKey
+	}
+
+	user, err := s.userRepo.FindByID(ctx, key.UserID())
+	if err != nil {
+		return nil, errors.New("failed to verify API key")
+	}
+	if user == nil {
+		return nil, ErrInvalidKey
+	}
+
+	if now.Sub(key.LastUsedAt()) >= lastUsedResolution {
+		// Tracking is best effort and must not fail the request.
+		_ = s.keyRepo.TouchLastUsed(ctx, key.ID(), now)
+	}
+
+	return auth.NewPrincipal("apikey:"+key.ID(), user.ID(), user.Role(), auth.MethodAPIKey, key.Scopes()), nil
+}
+
+func generate() (id, secret string, err error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	s := make([]byte, 32)
+	if _, err := rand.Read(s); err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(b), base64.RawURLEncoding.EncodeToString(s), nil
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,223 @@
+package apikey_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/auth"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/memory"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/apikey"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/policy"
+)
+
+// countingKeys counts the last-used writes.
+type countingKeys struct {
+	*memory.APIKeyRepository
+	touches int
+}
+
+func (r *countingKeys) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	r.touches++
+	return r.APIKeyRepository.TouchLastUsed(ctx, id, usedAt)
+}
+
+type env struct {
+	service *apikey.Service
+	keys    *countingKeys
+	users   *memory.UserRepository
+}
+
+func newEnv(t *testing.T) *env {
+	t.Helper()
+	e := &env{keys: &countingKeys{APIKeyRepository: memory.NewAPIKeyRepository()}, users: memory.NewUserRepository()}
+	e.service = apikey.NewService(e.keys, e.users)
+	return e
+}
+
+// signIn stores a user with role and returns a context authenticated as the
+// user with a token.
+func (e *env) signIn(t *testing.T, username string, role valueobject.Role) context.Context {
+	t.Helper()
+
+	email, _ := valueobject.NewEmail(username + "@example.com")
+	user := entity.NewUser(valueobject.UserID{}, username, username, email)
+	user.SetRole(role)
+	saved, err := e.users.Save(context.Background(), user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	principal := auth.NewPrincipal(saved.ID().String(), saved.ID(), role, auth.MethodJWT, nil)
+	return auth.WithPrincipal(context.Background(), principal)
+}
+
+func (e *env) create(t *testing.T, ctx context.Context, ttl time.Duration) (*entity.APIKey, string) {
+	t.Helper()
+
+	key, plaintext, err := e.service.Create(ctx, "ci", []string{auth.ScopePostsRead}, ttl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, plaintext
+}
+
+func TestAuthenticate(t *testing.T) {
+	e := newEnv(t)
+	ctx := e.signIn(t, "alice", valueobject.RoleModerator)
+	key, plaintext := e.create(t, ctx, 0)
+
+	principal, err := e.service.Authenticate(context.Background(), plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.UserID() != key.UserID() || principal.Role() != valueobject.RoleModerator ||
+		principal.Method() != auth.MethodAPIKey || !slices.Equal(principal.Scopes(), []string{auth.ScopePostsRead}) {
+		t.Errorf("principal = %+v", principal)
+	}
+	if principal.Subject() != "apikey:"+key.ID() {
+		t.Errorf("subject = %q", principal.Subject())
+	}
+	if strings.Contains(key.KeyHash(), plaintext) || !strings.HasPrefix(plaintext, "wak_"+key.ID()+"_") {
+		t.Errorf("key %q has hash %q", plaintext, key.KeyHash())
+	}
+
+	// Last use is written once per minute, not on every request.
+	if _, err := e.service.Authenticate(context.Background(), plaintext); err != nil {
+		t.Fatal(err)
+	}
+	if e.keys.touches != 1 {
+		t.Errorf("last-used writes = %d, want 1", e.keys.touches)
+	}
+	stored, _ := e.keys.FindByID(context.Background(), key.ID())
+	if stored.LastUsedAt().IsZero() {
+		t.Error("last use was not recorded")
+	}
+}
+
+func TestAuthenticateRejects(t *testing.T) {
+	e := newEnv(t)
+	ctx := e.signIn(t, "alice", valueobject.RoleUser)
+	key, plaintext := e.create(t, ctx, 0)
+	_, expired := e.create(t, ctx, time.Nanosecond)
+	revoked, revokedPlaintext := e.create(t, ctx, 0)
+	if err := e.service.Revoke(ctx, revoked.ID()); err != nil {
+		t.Fatal(err)
+	}
+
+	bobCtx := e.signIn(t, "bob", valueobject.RoleUser)
+	_, orphaned := e.create(t, bobCtx, 0)
+	if err := e.users.Delete(context.Background(), auth.PrincipalFromContext(bobCtx).UserID()); err != nil {
+		t.Fatal(err)
+	}
+
+	secret := strings.TrimPrefix(plaintext, "wak_"+key.ID()+"_")
+	tests := []struct {
+		name        string
+		credentials string
+	}{
+		{"empty", ""},
+		{"no prefix", key.ID() + "_" + secret},
+		{"other prefix", "sk_" + key.ID() + "_" + secret},
+		{"no secret", "wak_" + key.ID()},
+		{"no ID", "wak__" + secret},
+		{"unknown ID", "wak_0000000000000000_" + secret},
+		{"wrong secret", "wak_" + key.ID() + "_" + strings.ToUpper(secret) + "x"},
+		{"truncated secret", plaintext[:len(plaintext)-1]},
+		{"expired", expired},
+		{"revoked", revokedPlaintext},
+		{"owner deleted", orphaned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := e.service.Authenticate(context.Background(), tt.credentials)
+			if !errors.Is(err, apikey.ErrInvalidKey) {
+				t.Errorf("Authenticate = %v, %v, want ErrInvalidKey", principal, err)
+			}
+		})
+	}
+	if e.keys.touches != 0 {
+		t.Errorf("rejected keys recorded %d uses", e.keys.touches)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	e := newEnv(t)
+	alice := e.signIn(t, "alice", valueobject.RoleUser)
+	bob := e.signIn(t, "bob", valueobject.RoleModerator)
+	admin := e.signIn(t, "admin", valueobject.RoleAdmin)
+
+	tests := []struct {
+		name string
+		ctx  func(plaintext string) context.Context
+		want error
+	}{
+		{"owner", func(string) context.Context { return alice }, nil},
+		{"other user", func(string) context.Context { return bob }, apikey.ErrAPIKeyNotFound},
+		{"admin", func(string) context.Context { return admin }, nil},
+		{"anonymous", func(string) context.Context { return context.Background() }, policy.ErrUnauthenticated},
+		{
+			// Keys cannot manage keys, not even themselves.
+			name: "the key itself",
+			ctx: func(plaintext string) context.Context {
+				principal, err := e.service.Authenticate(context.Background(), plaintext)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return auth.WithPrincipal(context.Background(), principal)
+			},
+			want: policy.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, plaintext := e.create(t, alice, 0)
+
+			err := e.service.Revoke(tt.ctx(plaintext), key.ID())
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Revoke = %v, want %v", err, tt.want)
+			}
+			_, authErr := e.service.Authenticate(context.Background(), plaintext)
+			if revoked := authErr != nil; revoked != (tt.want == nil) {
+				t.Errorf("key revoked = %v, want %v", revoked, tt.want == nil)
+			}
+		})
+	}
+
+	if err := e.service.Revoke(alice, "missing"); !errors.Is(err, apikey.ErrAPIKeyNotFound) {
+		t.Errorf("Revoke of a missing key = %v, want ErrAPIKeyNotFound", err)
+	}
+}
+
+func TestCreateValidatesInput(t *testing.T) {
+	e := newEnv(t)
+	ctx := e.signIn(t, "alice", valueobject.RoleUser)
+
+	tests := []struct {
+		name   string
+		key    string
+		scopes []string
+		ttl    time.Duration
+	}{
+		{"no name", " ", []string{auth.ScopePostsRead}, 0},
+		{"no scopes", "ci", nil, 0},
+		{"unknown scope", "ci", []string{"admin"}, 0},
+		{"negative expiry", "ci", []string{auth.ScopePostsRead}, -time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := e.service.Create(ctx, tt.key, tt.scopes, tt.ttl); !errors.Is(err, apikey.ErrInvalidInput) {
+				t.Errorf("Create = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+	if keys, _ := e.service.List(ctx); len(keys) != 0 {
+		t.Errorf("keys = %d, want none", len(keys))
+	}
+}
@@ -0,0 +1,54 @@
+// Package datasync imports the users and posts of an external service into
+// the local database.
+package datasync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/span"
+)
+
+// UserSource and PostSource list the records of the external service, with
+// the IDs they have there.
+type UserSource interface {
+	FindAll(ctx context.Context) ([]*entity.User, error)
+}
+
+type PostSource interface {
+	FindAll(ctx context.Context) ([]*entity.Post, error)
+}
+
+type Service struct {
+	users      UserSource
+	posts      PostSource
+	importRepo repository.ImportRepository
+}
+
+func NewService(users UserSource, posts PostSource, importRepo repository.ImportRepository) *Service {
+	return &Service{
+		users:      users,
+		posts:      posts,
+		importRepo: importRepo,
+	}
+}
+
+// Sync fetches every user and post and stores them. With dryRun nothing is
+// written and the result tells what would change.
+func (s *Service) Sync(ctx context.Context, dryRun bool) (_ *repository.ImportResult, err error) {
+	ctx, end := span.Start(ctx, "datasync.Service.Sync")
+	defer end(&err)
+
+	users, err := s.users.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch users: %w", err)
+	}
+	posts, err := s.posts.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch posts: %w", err)
+	}
+
+	return s.importRepo.Import(ctx, users, posts, dryRun)
+}
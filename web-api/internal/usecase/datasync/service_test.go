@@ -0,0 +1,150 @@
+package datasync_test
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database/databasetest"
+	dbRepository "github.com/takagi_hisashi/go-best-practice/web-api/internal/infrastructure/database/repository"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/gateway/jsonplaceholder"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/interface/gateway/jsonplaceholder/jsonplaceholdertest"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/datasync"
+)
+
+// newService serves two users with three posts between them and imports
+// them into an empty SQLite database.
+func newService(t *testing.T) (*datasync.Service, *gorm.DB) {
+	t.Helper()
+
+	server := jsonplaceholdertest.NewServer()
+	t.Cleanup(server.Close)
+	leanne := server.AddUser(entity.NewUser(valueobject.UserID{}, "Leanne", "leanne", mustEmail(t, "leanne@example.com")))
+	ervin := server.AddUser(entity.NewUser(valueobject.UserID{}, "Ervin", "ervin", mustEmail(t, "ervin@example.com")))
+	server.AddPost(entity.NewPost(valueobject.PostID{}, leanne.ID(), "first", "body"))
+	server.AddPost(entity.NewPost(valueobject.PostID{}, leanne.ID(), "second", "body"))
+	server.AddPost(entity.NewPost(valueobject.PostID{}, ervin.ID(), "third", "body"))
+
+	db := databasetest.SQLite(t)
+	service := datasync.NewService(
+		jsonplaceholder.NewUserGateway(server.URL(), server.Client()),
+		jsonplaceholder.NewPostGateway(server.URL(), server.Client()),
+		dbRepository.NewImportRepository(db),
+	)
+	return service, db
+}
+
+func mustEmail(t *testing.T, s string) valueobject.Email {
+	t.Helper()
+	email, err := valueobject.NewEmail(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return email
+}
+
+func runSync(t *testing.T, service *datasync.Service, dryRun bool, want repository.ImportResult) {
+	t.Helper()
+	got, err := service.Sync(context.Background(), dryRun)
+	if err != nil {
+		t.Fatalf("Sync(dryRun=%t): %v", dryRun, err)
+	}
+	if *got != want {
+		t.Errorf("Sync(dryRun=%t) = %+v, want %+v", dryRun, *got, want)
+	}
+}
+
+func count(t *testing.T, db *gorm.DB, model any) int64 {
+	t.Helper()
+	var n int64
+	if err := db.Model(model).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestSyncIsIdempotent(t *testing.T) {
+	service, db := newService(t)
+
+	runSync(t, service, false, repository.ImportResult{
+		Users: repository.ImportCounts{Created: 2},
+		Posts: repository.ImportCounts{Created: 3},
+	})
+	runSync(t, service, false, repository.ImportResult{
+		Users: repository.ImportCounts{Unchanged: 2},
+		Posts: repository.ImportCounts{Unchanged: 3},
+	})
+
+	if n := count(t, db, &database.User{}); n != 2 {
+		t.Errorf("users = %d, want 2", n)
+	}
+	if n := count(t, db, &database.Post{}); n != 3 {
+		t.Errorf("posts = %d, want 3", n)
+	}
+}
+
+func TestSyncUpdatesChangedRecords(t *testing.T) {
+	service, db := newService(t)
+	runSync(t, service, false, repository.ImportResult{
+		Users: repository.ImportCounts{Created: 2},
+		Posts: repository.ImportCounts{Created: 3},
+	})
+
+	if err := db.Model(&database.Post{}).Where("external_id = ?", 2).Update("title", "edited").Error; err != nil {
+		t.Fatal(err)
+	}
+	runSync(t, service, false, repository.ImportResult{
+		Users: repository.ImportCounts{Unchanged: 2},
+		Posts: repository.ImportCounts{Updated: 1, Unchanged: 2},
+	})
+
+	var post database.Post
+	if err := db.Where("external_id = ?", 2).Take(&post).Error; err != nil {
+		t.Fatal(err)
+	}
+	if post.Title != "second" {
+		t.Errorf("title = %q, want %q", post.Title, "second")
+	}
+}
+
+func TestSyncDryRunWritesNothing(t *testing.T) {
+	service, db := newService(t)
+
+	want := repository.ImportResult{
+		Users: repository.ImportCounts{Created: 2},
+		Posts: repository.ImportCounts{Created: 3},
+	}
+	// The counts stay the same on a second dry run, since the first one
+	// was rolled back.
+	runSync(t, service, true, want)
+	runSync(t, service, true, want)
+
+	if n := count(t, db, &database.User{}); n != 0 {
+		t.Errorf("users = %d after dry runs, want 0", n)
+	}
+	if n := count(t, db, &database.Post{}); n != 0 {
+		t.Errorf("posts = %d after dry runs, want 0", n)
+	}
+
+	runSync(t, service, false, want)
+	if err := db.Model(&database.User{}).Where("external_id = ?", 1).Update("name", "edited").Error; err != nil {
+		t.Fatal(err)
+	}
+	runSync(t, service, true, repository.ImportResult{
+		Users: repository.ImportCounts{Updated: 1, Unchanged: 1},
+		Posts: repository.ImportCounts{Unchanged: 3},
+	})
+
+	var user database.User
+	if err := db.Where("external_id = ?", 1).Take(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "edited" {
+		t.Errorf("name = %q after a dry run, want the local %q", user.Name, "edited")
+	}
+}
@@ -0,0 +1,57 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/auth"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+)
+
+func principal(role valueobject.Role, method string, scopes ...string) context.Context {
+	userID, _ := valueobject.NewUserID(1)
+	return auth.WithPrincipal(context.Background(), auth.NewPrincipal("1", userID, role, method, scopes))
+}
+
+func TestCanManageUsers(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want error
+	}{
+		{"anonymous", context.Background(), ErrUnauthenticated},
+		{"user", principal(valueobject.RoleUser, auth.MethodJWT), ErrForbidden},
+		{"moderator", principal(valueobject.RoleModerator, auth.MethodJWT), ErrForbidden},
+		{"admin", principal(valueobject.RoleAdmin, auth.MethodJWT), nil},
+		{"admin key for posts", principal(valueobject.RoleAdmin, auth.MethodAPIKey, auth.ScopePostsRead), ErrForbidden},
+		{"admin key for users", principal(valueobject.RoleAdmin, auth.MethodAPIKey, auth.ScopeUsersRead), ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CanManageUsers(tt.ctx); !errors.Is(err, tt.want) {
+				t.Errorf("CanManageUsers = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanManageAPIKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want error
+	}{
+		{"anonymous", context.Background(), ErrUnauthenticated},
+		{"user", principal(valueobject.RoleUser, auth.MethodJWT), nil},
+		{"user key", principal(valueobject.RoleUser, auth.MethodAPIKey, auth.ScopePostsWrite), ErrForbidden},
+		{"admin key", principal(valueobject.RoleAdmin, auth.MethodAPIKey, auth.ScopePostsRead), ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CanManageAPIKeys(tt.ctx); !errors.Is(err, tt.want) {
+				t.Errorf("CanManageAPIKeys = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -3,10 +3,24 @@ package post
 import (
 	"context"
 	"errors"
+	"strings"
 
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/policy"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/span"
+)
+
+var (
+	ErrPostNotFound = errors.New("post not found")
+	ErrInvalidInput = errors.New("title is required")
+	// ErrInvalidID is returned for post and user IDs that are not positive
+	// integers.
+	ErrInvalidID = errors.New("invalid ID")
+	// ErrPostReadOnly is returned for changes to posts that were read from
+	// an external service and are not stored locally.
+	ErrPostReadOnly = errors.New("post is read-only")
 )
 
 type Service struct {
@@ -19,7 +33,14 @@ func NewService(postRepo repository.PostRepository) *Service {
 	}
 }
 
-func (s *Service) GetAllPosts(ctx context.Context) ([]*entity.Post, error) {
+func (s *Service) GetAllPosts(ctx context.Context) (_ []*entity.Post, err error) {
+	ctx, end := span.Start(ctx, "post.Service.GetAllPosts")
+	defer end(&err)
+
+	if err := policy.CanReadPosts(ctx); err != nil {
+		return nil, err
+	}
+
 	posts, err := s.postRepo.FindAll(ctx)
 	if err != nil {
 		return nil, errors.New("failed to get posts")
@@ -27,34 +48,124 @@ func (s *Service) GetAllPosts(ctx context.Context) ([]*entity.Post, error) {
 	return posts, nil
 }
 
-func (s *Service) GetPostByID(ctx context.Context, idStr string) (*entity.Post, error) {
+func (s *Service) GetPostByID(ctx context.Context, idStr string) (_ *entity.Post, err error) {
+	ctx, end := span.Start(ctx, "post.Service.GetPostByID")
+	defer end(&err)
+
+	if err := policy.CanReadPosts(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.findPost(ctx, idStr)
+}
+
+func (s *Service) findPost(ctx context.Context, idStr string) (*entity.Post, error) {
 	id, err := valueobject.NewPostIDFromString(idStr)
 	if err != nil {
-		return nil, err
+		return nil, ErrInvalidID
 	}
 
 	post, err := s.postRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, errors.New("failed to get post")
 	}
-	
+
 	if post == nil {
-		return nil, errors.New("post not found")
+		return nil, ErrPostNotFound
 	}
-	
+
 	return post, nil
 }
 
-func (s *Service) GetPostsByUserID(ctx context.Context, userIDStr string) ([]*entity.Post, error) {
+func (s *Service) GetPostsByUserID(ctx context.Context, userIDStr string) (_ []*entity.Post, err error) {
+	ctx, end := span.Start(ctx, "post.Service.GetPostsByUserID")
+	defer end(&err)
+
+	if err := policy.CanReadPosts(ctx); err != nil {
+		return nil, err
+	}
+
 	userID, err := valueobject.NewUserIDFromString(userIDStr)
 	if err != nil {
-		return nil, err
+		return nil, ErrInvalidID
 	}
 
 	posts, err := s.postRepo.FindByUserID(ctx, userID)
 	if err != nil {
 		return nil, errors.New("failed to get posts")
 	}
-	
+
 	return posts, nil
-}
\ No newline at end of file
+}
+
+// CreatePost creates a post authored by the authenticated user.
+func (s *Service) CreatePost(ctx context.Context, title, body string) (_ *entity.Post, err error) {
+	ctx, end := span.Start(ctx, "post.Service.CreatePost")
+	defer end(&err)
+
+	principal, err := policy.CanCreatePost(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, ErrInvalidInput
+	}
+
+	post, err := s.postRepo.Save(ctx, entity.NewPost(valueobject.PostID{}, principal.UserID(), title, body))
+	if err != nil {
+		return nil, errors.New("failed to create post")
+	}
+	return post, nil
+}
+
+func (s *Service) UpdatePost(ctx context.Context, idStr, title, body string) (_ *entity.Post, err error) {
+	ctx, end := span.Start(ctx, "post.Service.UpdatePost")
+	defer end(&err)
+
+	post, err := s.findPost(ctx, idStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := policy.CanModifyPost(ctx, post); err != nil {
+		return nil, err
+	}
+	if post.ReadOnly() {
+		return nil, ErrPostReadOnly
+	}
+
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, ErrInvalidInput
+	}
+
+	updated := entity.NewPost(post.ID(), post.UserID(), title, body)
+	if err := s.postRepo.Update(ctx, updated); err != nil {
+		return nil, errors.New("failed to update post")
+	}
+	return updated, nil
+}
+
+func (s *Service) DeletePost(ctx context.Context, idStr string) (err error) {
+	ctx, end := span.Start(ctx, "post.Service.DeletePost")
+	defer end(&err)
+
+	post, err := s.findPost(ctx, idStr)
+	if err != nil {
+		return err
+	}
+
+	if err := policy.CanModifyPost(ctx, post); err != nil {
+		return err
+	}
+	if post.ReadOnly() {
+		return ErrPostReadOnly
+	}
+
+	if err := s.postRepo.Delete(ctx, post.ID()); err != nil {
+		return errors.New("failed to delete post")
+	}
+	return nil
+}
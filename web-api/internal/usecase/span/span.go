@@ -0,0 +1,29 @@
+// Package span traces use case methods. It only records spans through the
+// OpenTelemetry API; exporting them is set up by the infrastructure.
+package span
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var tracer = otel.Tracer("github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase")
+
+// Start starts a child span for a service method, e.g.
+// "post.Service.GetPost". The returned func ends it and records the error
+// the method returned, if any:
+//
+//	ctx, end := span.Start(ctx, "post.Service.GetPost")
+//	defer end(&err)
+func Start(ctx context.Context, name string) (context.Context, func(*error)) {
+	ctx, s := tracer.Start(ctx, name)
+	return ctx, func(err *error) {
+		if *err != nil {
+			s.RecordError(*err)
+			s.SetStatus(codes.Error, (*err).Error())
+		}
+		s.End()
+	}
+}
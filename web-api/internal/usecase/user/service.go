@@ -7,6 +7,18 @@ import (
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/entity"
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/repository"
 	"github.com/takagi_hisashi/go-best-practice/web-api/internal/domain/valueobject"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/policy"
+	"github.com/takagi_hisashi/go-best-practice/web-api/internal/usecase/span"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrInvalidRole  = errors.New("invalid role")
+	// ErrInvalidID is returned for user IDs that are not positive integers.
+	ErrInvalidID = errors.New("invalid user ID")
+	// ErrUserReadOnly is returned for changes to users that were read from
+	// an external service and are not stored locally.
+	ErrUserReadOnly = errors.New("user is read-only")
 )
 
 type Service struct {
@@ -19,7 +31,14 @@ func NewService(userRepo repository.UserRepository) *Service {
 	}
 }
 
-func (s *Service) GetAllUsers(ctx context.Context) ([]*entity.User, error) {
+func (s *Service) GetAllUsers(ctx context.Context) (_ []*entity.User, err error) {
+	ctx, end := span.Start(ctx, "user.Service.GetAllUsers")
+	defer end(&err)
+
+	if err := policy.CanReadUsers(ctx); err != nil {
+		return nil, err
+	}
+
 	users, err := s.userRepo.FindAll(ctx)
 	if err != nil {
 		return nil, errors.New("failed to get users")
@@ -27,25 +46,43 @@ func (s *Service) GetAllUsers(ctx context.Context) ([]*entity.User, error) {
 	return users, nil
 }
 
-func (s *Service) GetUserByID(ctx context.Context, idStr string) (*entity.User, error) {
+func (s *Service) GetUserByID(ctx context.Context, idStr string) (_ *entity.User, err error) {
+	ctx, end := span.Start(ctx, "user.Service.GetUserByID")
+	defer end(&err)
+
+	if err := policy.CanReadUsers(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.findUser(ctx, idStr)
+}
+
+func (s *Service) findUser(ctx context.Context, idStr string) (*entity.User, error) {
 	id, err := valueobject.NewUserIDFromString(idStr)
 	if err != nil {
-		return nil, err
+		return nil, ErrInvalidID
 	}
 
 	user, err := s.userRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, errors.New("failed to get user")
 	}
-	
+
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
-	
+
 	return user, nil
 }
 
-func (s *Service) GetUserByEmail(ctx context.Context, emailStr string) (*entity.User, error) {
+func (s *Service) GetUserByEmail(ctx context.Context, emailStr string) (_ *entity.User, err error) {
+	ctx, end := span.Start(ctx, "user.Service.GetUserByEmail")
+	defer end(&err)
+
+	if err := policy.CanReadUsers(ctx); err != nil {
+		return nil, err
+	}
+
 	email, err := valueobject.NewEmail(emailStr)
 	if err != nil {
 		return nil, err
@@ -55,10 +92,57 @@ func (s *Service) GetUserByEmail(ctx context.Context, emailStr string) (*entity.
 	if err != nil {
 		return nil, errors.New("failed to get user")
 	}
-	
+
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
-	
+
 	return user, nil
-}
\ No newline at end of file
+}
+
+// ListUsers returns all users for administration. Only admins signed in
+// as themselves, not through an API key, may call it.
+func (s *Service) ListUsers(ctx context.Context) (_ []*entity.User, err error) {
+	ctx, end := span.Start(ctx, "user.Service.ListUsers")
+	defer end(&err)
+
+	if _, err := policy.CanManageUsers(ctx); err != nil {
+		return nil, err
+	}
+
+	users, err := s.userRepo.FindAll(ctx)
+	if err != nil {
+		return nil, errors.New("failed to get users")
+	}
+	return users, nil
+}
+
+// ChangeRole assigns a new role to a user. Only admins signed in as
+// themselves, not through an API key, may call it.
+func (s *Service) ChangeRole(ctx context.Context, idStr, roleStr string) (_ *entity.User, err error) {
+	ctx, end := span.Start(ctx, "user.Service.ChangeRole")
+	defer end(&err)
+
+	if _, err := policy.CanManageUsers(ctx); err != nil {
+		return nil, err
+	}
+
+	role, err := valueobject.NewRole(roleStr)
+	if err != nil {
+		return nil, ErrInvalidRole
+	}
+
+	user, err := s.findUser(ctx, idStr)
+	if err != nil {
+		return nil, err
+	}
+	if user.ReadOnly() {
+		return nil, ErrUserReadOnly
+	}
+
+	user.SetRole(role)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, errors.New("failed to update user")
+	}
+	return user, nil
+}